@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rafa-mori/gocrafter/internal/generator"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+)
+
+// CacheCommand creates the download cache management command
+func CacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the kit download cache",
+		Long: `Manage the cache of downloaded kits: archives, stored by the hash of their
+contents, and git mirrors. kit add and kit update fetch through it, only
+downloading what changed, and serve from it alone with --offline.
+
+Entries unused for max_cache_age_days are evicted, then the least recently
+used ones until the cache fits max_cache_size_mb (see 'gocrafter config').`,
+		Example: `  # Show cached downloads
+  gocrafter cache list
+
+  # Evict entries over the age and size limits
+  gocrafter cache prune
+
+  # Remove everything
+  gocrafter cache clear`,
+		Annotations: GetDescriptions([]string{"Manage the kit download cache", "Manage the cache of downloaded kit archives and git mirrors."}, false),
+	}
+
+	cmd.AddCommand(
+		cacheListCommand(),
+		cachePruneCommand(),
+		cacheClearCommand(),
+	)
+
+	return cmd
+}
+
+func cacheListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls", "l"},
+		Short:   "List cached downloads",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheListCommand()
+		},
+	}
+}
+
+func cachePruneCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Evict downloads over the age and size limits",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCachePruneCommand()
+		},
+	}
+}
+
+func cacheClearCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove every cached download",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCacheClearCommand()
+		},
+	}
+}
+
+// downloadCache returns the configured download cache
+func downloadCache() (*generator.DownloadCache, error) {
+	kitManager, err := generator.NewKitManager(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kit manager: %w", err)
+	}
+	return kitManager.Downloads(), nil
+}
+
+func runCacheListCommand() error {
+	cache, err := downloadCache()
+	if err != nil {
+		return err
+	}
+	entries, err := cache.Entries()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		gl.Log("info", fmt.Sprintf("Download cache is empty (%s)", cache.Dir()))
+		return nil
+	}
+
+	size, err := cache.Size()
+	if err != nil {
+		return err
+	}
+	maxAge, maxSize := cache.Limits()
+	limit := "no size limit"
+	if maxSize > 0 {
+		limit = "limit " + formatBytes(maxSize)
+	}
+	gl.Log("info", fmt.Sprintf("🗄️  Download cache (%s): %d entries, %s, %s", cache.Dir(), len(entries), formatBytes(size), limit))
+	gl.Log("info", "")
+
+	for _, entry := range entries {
+		gl.Log("info", fmt.Sprintf("📦 %s", entry.URL))
+		gl.Log("info", fmt.Sprintf("   %s, %s, used %s ago", entry.Kind, formatBytes(entry.Size), formatAge(time.Since(entry.Used))))
+		if entry.Ref != "" {
+			gl.Log("info", fmt.Sprintf("   Ref: %s", entry.Ref))
+		}
+		if maxAge > 0 && time.Since(entry.Used) > maxAge {
+			gl.Log("info", "   Expired; removed by the next prune")
+		}
+		gl.Log("info", "")
+	}
+	return nil
+}
+
+func runCachePruneCommand() error {
+	cache, err := downloadCache()
+	if err != nil {
+		return err
+	}
+	before, err := cache.Size()
+	if err != nil {
+		return err
+	}
+
+	removed, err := cache.Prune()
+	if err != nil {
+		return err
+	}
+	for _, entry := range removed {
+		gl.Log("info", fmt.Sprintf("🗑️  %s", entry.URL))
+	}
+
+	after, err := cache.Size()
+	if err != nil {
+		return err
+	}
+	gl.Log("info", fmt.Sprintf("Pruned %d entries, freed %s", len(removed), formatBytes(before-after)))
+	return nil
+}
+
+func runCacheClearCommand() error {
+	cache, err := downloadCache()
+	if err != nil {
+		return err
+	}
+	size, err := cache.Size()
+	if err != nil {
+		return err
+	}
+
+	if err := cache.Clear(); err != nil {
+		return err
+	}
+	gl.Log("info", fmt.Sprintf("Download cache cleared, freed %s", formatBytes(size)))
+	return nil
+}
+
+// formatBytes renders a size with a binary unit
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// formatAge renders a duration in its largest whole unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+	return fmt.Sprintf("%ds", int(d/time.Second))
+}
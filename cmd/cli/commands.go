@@ -8,8 +8,13 @@ import (
 func GetCommands() []*cobra.Command {
 	return []*cobra.Command{
 		NewCommand(),
+		RegenCommand(),
 		ListCommand(),
 		InfoCommand(),
 		KitCommand(),
+		ProfileCommand(),
+		LicenseCommand(),
+		ConfigCommand(),
+		CacheCommand(),
 	}
 }
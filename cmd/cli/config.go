@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/config"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+)
+
+// ConfigCommand creates the configuration management command
+func ConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage GoCrafter configuration",
+		Long: `Manage the GoCrafter configuration file.
+
+The file is ~/.gocrafter/config.yaml, or $XDG_CONFIG_HOME/gocrafter/config.yaml
+when XDG_CONFIG_HOME is set, or $GOCRAFTER_CONFIG. Every key has a default and
+an environment variable that overrides the file; see 'gocrafter config list'.`,
+		Example: `  # Show every setting and where it comes from
+  gocrafter config list
+
+  # Refresh installed kits older than three days when generating
+  gocrafter config set auto_update true
+  gocrafter config set max_cache_age_days 3
+
+  # Open the file in $EDITOR
+  gocrafter config edit`,
+		Annotations: GetDescriptions([]string{"Manage GoCrafter configuration", "Manage the GoCrafter configuration file."}, false),
+	}
+
+	cmd.AddCommand(
+		configGetCommand(),
+		configSetCommand(),
+		configListCommand(),
+		configEditCommand(),
+		configPathCommand(),
+	)
+
+	return cmd
+}
+
+func configGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setting, err := config.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(setting.Value)
+			return nil
+		},
+	}
+}
+
+func configSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a key in the config file",
+		Long: `Set a key in the config file, creating the file if needed.
+
+Lists, such as registries, are comma-separated. An empty value removes the
+key from the file, restoring its default.`,
+		Args: cobra.ExactArgs(2),
+		Example: `  gocrafter config set auto_update true
+  gocrafter config set registries https://example.com/kits/index.yaml,/srv/kits/index.yaml
+  gocrafter config set cache_path ""`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigSetCommand(args[0], args[1])
+		},
+	}
+}
+
+func configListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls", "l"},
+		Short:   "List every setting and where its value comes from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigListCommand()
+		},
+	}
+}
+
+func configEditCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $VISUAL or $EDITOR",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigEditCommand()
+		},
+	}
+}
+
+func configPathCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "path",
+		Short: "Print the config file path",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := config.Path()
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+}
+
+func runConfigSetCommand(key, value string) error {
+	if err := config.Set(key, value); err != nil {
+		return err
+	}
+
+	if value == "" {
+		gl.Log("info", fmt.Sprintf("Config key '%s' reset to its default", key))
+	} else {
+		gl.Log("info", fmt.Sprintf("Config updated: %s = %s", key, value))
+	}
+	if setting, err := config.Get(key); err == nil && setting.Source == config.SourceEnv {
+		gl.Log("warn", fmt.Sprintf("$%s is set and overrides the config file", setting.Env))
+	}
+	return nil
+}
+
+func runConfigListCommand() error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	settings, err := config.List()
+	if err != nil {
+		return err
+	}
+
+	gl.Log("info", fmt.Sprintf("⚙️  Configuration (%s):", path))
+	gl.Log("info", "")
+	for _, setting := range settings {
+		source := setting.Source
+		if source == config.SourceEnv {
+			source = "$" + setting.Env
+		}
+		gl.Log("info", fmt.Sprintf("   %s = %s (%s)", setting.Key, setting.Value, source))
+	}
+	return nil
+}
+
+func runConfigEditCommand() error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := writeConfigTemplate(path); err != nil {
+			return err
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", editor, err)
+	}
+
+	if err := config.Validate(path); err != nil {
+		return fmt.Errorf("config file %s is invalid: %w", path, err)
+	}
+	gl.Log("info", fmt.Sprintf("Config saved: %s", path))
+	return nil
+}
+
+// writeConfigTemplate creates a config file listing every key, commented out,
+// with its default and environment variable
+func writeConfigTemplate(path string) error {
+	settings, err := config.List()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString("# GoCrafter configuration. Uncomment a key to change it;\n")
+	b.WriteString("# the environment variable next to it overrides this file.\n\n")
+	for _, setting := range settings {
+		// Only lists default to empty
+		value := setting.Value
+		if value == "" {
+			value = "[]"
+		}
+		fmt.Fprintf(&b, "# %s: %s  # $%s\n", setting.Key, value, setting.Env)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
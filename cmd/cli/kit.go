@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -31,7 +32,13 @@ func KitCommand() *cobra.Command {
   gocrafter kit update my-go-kit
 
   # Show kit information
-  gocrafter kit info my-go-kit`,
+  gocrafter kit info my-go-kit
+
+  # Find kits in the registries
+  gocrafter kit search grpc --language go
+
+  # Check a kit for problems
+  gocrafter kit lint ./my-go-kit`,
 		Annotations: GetDescriptions([]string{"Manage project kits", "Manage pluggable project kits for generating different types of projects."}, false),
 	}
 
@@ -42,34 +49,63 @@ func KitCommand() *cobra.Command {
 		kitRemoveCommand(),
 		kitUpdateCommand(),
 		kitInfoCommand(),
+		kitLintCommand(),
+		kitDevCommand(),
+		kitSearchCommand(),
 	)
 
 	return cmd
 }
 
 func kitAddCommand() *cobra.Command {
-	var force bool
+	var force, link, offline bool
+	var templatesDir string
+	var registries []string
 
 	cmd := &cobra.Command{
-		Use:     "add <repository-url>",
+		Use:     "add <repository-url|name>",
 		Aliases: []string{"install", "a"},
 		Short:   "Add a new kit from repository",
-		Long:    `Add a new project kit from a Git repository or archive URL.`,
-		Args:    cobra.ExactArgs(1),
+		Long: `Add a new project kit from a Git repository or archive URL, or by
+name from the registry indexes (see kit search).
+
+URLs ending in .tar.gz are downloaded as archives. Other URLs are cloned with
+git; when that fails, an http(s) URL is downloaded as a .tar.gz archive
+instead, for servers that don't name their tarballs that way.
+
+Use template:<name> to install a built-in (or --templates-dir) template as a
+regular kit that can then be customized.
+
+With --link, a local kit directory is registered by reference instead of
+copied: edits to it are picked up without reinstalling, and removing the
+kit only unregisters it.`,
+		Args: cobra.ExactArgs(1),
 		Example: `  # Add kit from GitHub
   gocrafter kit add https://github.com/user/golang-api-kit
 
   # Add kit from archive
   gocrafter kit add https://example.com/kits/web-kit.tar.gz
 
+  # Add kit by name from a registry
+  gocrafter kit add grpc-service --registry https://example.com/kits/index.yaml
+
+  # Install a built-in template as a regular kit
+  gocrafter kit add template:api-rest
+
+  # Link a kit under development
+  gocrafter kit add --link ./my-kit
+
   # Force add (overwrite existing)
   gocrafter kit add --force https://github.com/user/my-kit`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runKitAddCommand(args[0], force)
+			return runKitAddCommand(args[0], templatesDir, force, link, registries, offline)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force add kit (overwrite if exists)")
+	cmd.Flags().BoolVar(&link, "link", false, "Register a local kit directory by reference instead of copying it")
+	cmd.Flags().StringVar(&templatesDir, "templates-dir", "", "Directory of templates searched before the built-in ones (default $GOCRAFTER_TEMPLATES)")
+	addRegistryFlags(cmd, &registries, &offline)
 	return cmd
 }
 
@@ -80,7 +116,13 @@ func kitListCommand() *cobra.Command {
 		Use:     "list",
 		Aliases: []string{"ls", "l"},
 		Short:   "List all available kits",
-		Long:    `List all installed project kits with their information.`,
+		Long: `List all installed project kits with their information.
+
+Kits are read from several roots, in precedence order: the .gocrafter/kits
+directory of the current repository, the $GOCRAFTER_KITS_PATH directories,
+the user kits and ` + generator.SystemKitsPath + `. A kit shadows the kits of
+the same name in later roots; use <root>:<name>, e.g. system:go-api, to
+select one of those.`,
 		Example: `  # List all kits
   gocrafter kit list
 
@@ -119,22 +161,29 @@ func kitRemoveCommand() *cobra.Command {
 }
 
 func kitUpdateCommand() *cobra.Command {
+	var offline bool
+
 	cmd := &cobra.Command{
 		Use:     "update <kit-name>",
 		Aliases: []string{"upgrade", "u"},
 		Short:   "Update a kit",
-		Long:    `Update an installed project kit to the latest version.`,
-		Args:    cobra.ExactArgs(1),
+		Long: `Update an installed project kit to the latest version.
+
+Kits are fetched through the download cache: a git repository is only fetched
+if its HEAD moved, and an archive only if the server reports a change. With
+--offline the kit is reinstalled from the cache without any network access.`,
+		Args: cobra.ExactArgs(1),
 		Example: `  # Update a specific kit
   gocrafter kit update my-go-kit
 
   # Update all kits
   gocrafter kit update --all`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runKitUpdateCommand(args[0])
+			return runKitUpdateCommand(args[0], offline)
 		},
 	}
 
+	cmd.Flags().BoolVar(&offline, "offline", false, "Reinstall the kit from the download cache only")
 	return cmd
 }
 
@@ -155,27 +204,66 @@ func kitInfoCommand() *cobra.Command {
 	return cmd
 }
 
-// Command implementations
+func kitLintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lint <kit-name|path>",
+		Aliases: []string{"check"},
+		Short:   "Check a kit for problems",
+		Long:    `Check an installed kit or a local kit directory for problems such as missing partials.`,
+		Args:    cobra.ExactArgs(1),
+		Example: `  # Lint an installed kit
+  gocrafter kit lint my-go-kit
+
+  # Lint a kit under development
+  gocrafter kit lint ./my-go-kit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKitLintCommand(args[0])
+		},
+	}
 
-func runKitAddCommand(repoURL string, force bool) error {
-	gl.Log("info", fmt.Sprintf("Adding kit from repository: %s", repoURL))
+	return cmd
+}
+
+// Command implementations
 
+func runKitAddCommand(repoURL, templatesDir string, force, link bool, registries []string, offline bool) error {
 	// Initialize kit manager
-	kitManager, err := generator.NewKitManager(nil)
+	kitManager, err := newRegistryKitManager(registries, offline)
 	if err != nil {
-		return fmt.Errorf("failed to initialize kit manager: %w", err)
+		return err
 	}
 
 	// Extract kit name for force check
-	kitName := extractKitNameFromURL(repoURL)
+	var (
+		kitName string
+		entry   *generator.IndexEntry
+	)
+	if link {
+		if strings.HasPrefix(repoURL, generator.TemplateKitPrefix) || strings.Contains(repoURL, "://") {
+			return fmt.Errorf("--link requires a local kit directory")
+		}
+		kit, err := kitManager.LoadKit(repoURL)
+		if err != nil {
+			return fmt.Errorf("kit validation failed: %w", err)
+		}
+		gl.Log("info", fmt.Sprintf("Linking kit from directory: %s", kit.LocalPath))
+		kitName = generator.LinkName(kit)
+	} else if generator.IsIndexedName(repoURL) {
+		if entry, err = kitManager.ResolveKit(repoURL); err != nil {
+			return err
+		}
+		kitName = entry.Name
+	} else {
+		gl.Log("info", fmt.Sprintf("Adding kit from repository: %s", repoURL))
+		kitName = extractKitNameFromURL(repoURL)
+	}
 	if kitName == "" {
 		return fmt.Errorf("could not extract kit name from URL")
 	}
 
 	// Check if kit already exists
 	if !force {
-		_, err := kitManager.GetKit(kitName)
-		if err == nil {
+		if kitExists(kitManager, generator.UserKitRoot, kitName) {
 			// Kit exists, ask for confirmation
 			var overwrite bool
 			prompt := &survey.Confirm{
@@ -195,7 +283,7 @@ func runKitAddCommand(repoURL string, force bool) error {
 		}
 	} else {
 		// Force mode: remove existing kit if it exists
-		if _, err := kitManager.GetKit(kitName); err == nil {
+		if kitExists(kitManager, generator.UserKitRoot, kitName) {
 			if err := kitManager.RemoveKit(kitName); err != nil {
 				gl.Log("warn", fmt.Sprintf("Failed to remove existing kit: %v", err))
 			}
@@ -203,7 +291,27 @@ func runKitAddCommand(repoURL string, force bool) error {
 	}
 
 	// Add the kit
-	if err := kitManager.AddKit(repoURL); err != nil {
+	if link {
+		if _, err := kitManager.LinkKit(repoURL); err != nil {
+			return fmt.Errorf("failed to link kit: %w", err)
+		}
+	} else if entry != nil {
+		if err := kitManager.AddIndexedKit(entry); err != nil {
+			return fmt.Errorf("failed to add kit: %w", err)
+		}
+	} else if strings.HasPrefix(repoURL, generator.TemplateKitPrefix) {
+		catalog, err := loadTemplateCatalog(templatesDir)
+		if err != nil {
+			return err
+		}
+		tmpl, err := catalog.Get(kitName)
+		if err != nil {
+			return err
+		}
+		if err := kitManager.InstallTemplate(tmpl); err != nil {
+			return fmt.Errorf("failed to add kit: %w", err)
+		}
+	} else if err := kitManager.AddKit(repoURL); err != nil {
 		return fmt.Errorf("failed to add kit: %w", err)
 	}
 
@@ -230,6 +338,14 @@ func runKitListCommand(verbose bool) error {
 		return nil
 	}
 
+	if verbose {
+		gl.Log("info", "📂 Kit roots:")
+		for _, root := range kitManager.Roots() {
+			gl.Log("info", fmt.Sprintf("   %s: %s", root.Name, root.Path))
+		}
+		gl.Log("info", "")
+	}
+
 	gl.Log("info", fmt.Sprintf("📦 Installed Kits (%d):", len(kits)))
 	gl.Log("info", "")
 
@@ -252,8 +368,7 @@ func runKitRemoveCommand(kitName string, force bool) error {
 	}
 
 	// Check if kit exists
-	_, err = kitManager.GetKit(kitName)
-	if err != nil {
+	if !kitExists(kitManager, "", kitName) {
 		return fmt.Errorf("kit '%s' not found", kitName)
 	}
 
@@ -280,12 +395,13 @@ func runKitRemoveCommand(kitName string, force bool) error {
 	return nil
 }
 
-func runKitUpdateCommand(kitName string) error {
+func runKitUpdateCommand(kitName string, offline bool) error {
 	// Initialize kit manager
 	kitManager, err := generator.NewKitManager(nil)
 	if err != nil {
 		return fmt.Errorf("failed to initialize kit manager: %w", err)
 	}
+	kitManager.SetOffline(offline)
 
 	// Update the kit
 	if err := kitManager.UpdateKit(kitName); err != nil {
@@ -327,9 +443,56 @@ func runKitInfoCommand(kitName string) error {
 	return nil
 }
 
+func runKitLintCommand(target string) error {
+	// Initialize kit manager
+	kitManager, err := generator.NewKitManager(nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize kit manager: %w", err)
+	}
+
+	// Resolve the kit directory from a name or a path
+	kitPath := target
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		kit, err := kitManager.GetKit(target)
+		if err != nil {
+			return fmt.Errorf("kit '%s' not found", target)
+		}
+		kitPath = kit.LocalPath
+	}
+
+	issues, err := kitManager.LintKit(kitPath)
+	if err != nil {
+		return fmt.Errorf("failed to lint kit: %w", err)
+	}
+
+	if len(issues) == 0 {
+		gl.Log("info", fmt.Sprintf("✅ No problems found in %s", kitPath))
+		return nil
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		level := "warn"
+		if issue.Severity == "error" {
+			level = "error"
+			errorCount++
+		}
+		gl.Log(level, issue.String())
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("kit lint found %d error(s)", errorCount)
+	}
+	return nil
+}
+
 // Helper functions
 
 func extractKitNameFromURL(repoURL string) string {
+	if name, ok := strings.CutPrefix(repoURL, generator.TemplateKitPrefix); ok {
+		return name
+	}
+
 	if strings.Contains(repoURL, "github.com") {
 		parts := strings.Split(repoURL, "/")
 		if len(parts) >= 2 {
@@ -337,15 +500,30 @@ func extractKitNameFromURL(repoURL string) string {
 			return strings.TrimSuffix(name, ".git")
 		}
 	}
-	
+
 	parts := strings.Split(strings.TrimSuffix(repoURL, "/"), "/")
 	if len(parts) > 0 {
 		return strings.TrimSuffix(parts[len(parts)-1], ".git")
 	}
-	
+
 	return ""
 }
 
+// kitExists reports whether a kit is in root, or in any root if root is
+// empty; linked kits count even if their link is broken
+func kitExists(kitManager *generator.KitManagerImpl, root, kitName string) bool {
+	if root == "" || root == generator.UserKitRoot {
+		if _, ok := kitManager.LinkedPath(kitName); ok {
+			return true
+		}
+	}
+	if root != "" {
+		kitName = generator.QualifiedKitName(root, kitName)
+	}
+	_, err := kitManager.GetKit(kitName)
+	return err == nil
+}
+
 func printKitSummary(kit types.Kit) {
 	gl.Log("info", fmt.Sprintf("📦 %s", kit.Name))
 	if kit.Description != "" {
@@ -354,12 +532,36 @@ func printKitSummary(kit types.Kit) {
 	if kit.Language != "" {
 		gl.Log("info", fmt.Sprintf("   Language: %s", kit.Language))
 	}
+	printKitRoot(kit)
+	printKitLink(kit)
 	gl.Log("info", "")
 }
 
+// printKitRoot shows which root a kit comes from and whether it is shadowed
+func printKitRoot(kit types.Kit) {
+	if kit.Root == "" {
+		return
+	}
+	gl.Log("info", fmt.Sprintf("   Root: %s", kit.Root))
+	if kit.ShadowedBy != "" {
+		gl.Log("warn", fmt.Sprintf("   Shadowed by the %s kit of the same name", kit.ShadowedBy))
+	}
+}
+
+// printKitLink shows where a linked kit is read from and why it is broken
+func printKitLink(kit types.Kit) {
+	if !kit.Linked {
+		return
+	}
+	gl.Log("info", fmt.Sprintf("   🔗 Linked: %s", kit.LocalPath))
+	if kit.Broken != "" {
+		gl.Log("warn", fmt.Sprintf("   ⚠️  Broken: %s", kit.Broken))
+	}
+}
+
 func printKitDetailed(kit types.Kit) {
 	gl.Log("info", fmt.Sprintf("📦 %s", kit.Name))
-	
+
 	if kit.Description != "" {
 		gl.Log("info", fmt.Sprintf("   Description: %s", kit.Description))
 	}
@@ -375,20 +577,23 @@ func printKitDetailed(kit types.Kit) {
 	if kit.Repository != "" {
 		gl.Log("info", fmt.Sprintf("   Repository: %s", kit.Repository))
 	}
-	if kit.LocalPath != "" {
+	printKitRoot(kit)
+	if kit.Linked {
+		printKitLink(kit)
+	} else if kit.LocalPath != "" {
 		gl.Log("info", fmt.Sprintf("   Path: %s", kit.LocalPath))
 	}
-	
+
 	if len(kit.Dependencies) > 0 {
 		gl.Log("info", "   Dependencies:")
 		for _, dep := range kit.Dependencies {
 			gl.Log("info", fmt.Sprintf("     • %s", dep))
 		}
 	}
-	
+
 	if len(kit.Tags) > 0 {
 		gl.Log("info", fmt.Sprintf("   Tags: %s", strings.Join(kit.Tags, ", ")))
 	}
-	
+
 	gl.Log("info", "")
 }
@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rafa-mori/gocrafter/internal/engine"
+	"github.com/rafa-mori/gocrafter/internal/generator"
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// kitDevDebounce is how long to wait for more changes before re-rendering;
+// editors often write a file in several steps
+const kitDevDebounce = 150 * time.Millisecond
+
+func kitDevCommand() *cobra.Command {
+	var (
+		valuesFile string
+		previewDir string
+		watch      bool
+		execCmd    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dev <kit-path>",
+		Short: "Render a kit under development into a preview directory",
+		Long: `Render a local kit in place, without installing it, into a preview directory.
+
+Placeholder values come from a YAML values file. Templates are rendered
+strictly, so a file that fails to parse or execute is reported as file:line.
+With --watch, the kit and the values file are watched and the preview is
+regenerated on every change, rewriting only the files whose inputs changed.
+--exec runs a command in the preview directory after every successful render.`,
+		Args: cobra.ExactArgs(1),
+		Example: `  # Render once
+  gocrafter kit dev ./my-kit --values values.yaml
+
+  # Re-render on every change and check that the result builds
+  gocrafter kit dev ./my-kit --values values.yaml --watch --exec "go build ./..."`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKitDevCommand(args[0], valuesFile, previewDir, watch, execCmd)
+		},
+	}
+
+	cmd.Flags().StringVarP(&valuesFile, "values", "f", "", "YAML file of placeholder values")
+	cmd.Flags().StringVarP(&previewDir, "preview", "o", "", "Preview directory (default: <tmp>/gocrafter-preview/<kit>)")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Re-render whenever the kit or the values file changes")
+	cmd.Flags().StringVar(&execCmd, "exec", "", "Command run in the preview directory after every successful render")
+	return cmd
+}
+
+func runKitDevCommand(kitPath, valuesFile, previewDir string, watch bool, execCmd string) error {
+	kitManager, err := generator.NewKitManager(nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize kit manager: %w", err)
+	}
+
+	kit, err := kitManager.LoadKit(kitPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kit: %w", err)
+	}
+	if previewDir == "" {
+		previewDir = filepath.Join(os.TempDir(), "gocrafter-preview", kit.Name)
+	}
+
+	dev := &kitDev{
+		manager: kitManager,
+		kitPath: kit.LocalPath,
+		exec:    execCmd,
+	}
+	if dev.previewDir, err = filepath.Abs(previewDir); err != nil {
+		return fmt.Errorf("failed to resolve preview directory: %w", err)
+	}
+	if valuesFile != "" {
+		if dev.valuesFile, err = filepath.Abs(valuesFile); err != nil {
+			return fmt.Errorf("failed to resolve values file: %w", err)
+		}
+	}
+	gl.Log("info", fmt.Sprintf("📁 Preview: %s", dev.previewDir))
+
+	if !watch {
+		return dev.render()
+	}
+
+	dev.rebuild()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return dev.watch(ctx)
+}
+
+// kitDev renders a kit under development into a preview directory
+type kitDev struct {
+	manager    *generator.KitManagerImpl
+	kitPath    string // Absolute
+	valuesFile string // Absolute, empty for none
+	previewDir string // Absolute
+	exec       string
+}
+
+// render regenerates the preview, reloading the kit and the values file
+func (d *kitDev) render() error {
+	start := time.Now()
+
+	kit, err := d.manager.LoadKit(d.kitPath)
+	if err != nil {
+		return fmt.Errorf("failed to load kit: %w", err)
+	}
+	values, err := loadDevValues(d.valuesFile)
+	if err != nil {
+		return err
+	}
+
+	// The preview always mirrors the kit, so edits to it are overwritten
+	kitGenerator := generator.NewKitGenerator(d.manager).
+		WithStrict(true).
+		WithIncremental(generator.IncrementalOptions{Enabled: true, Prune: true, Force: true})
+
+	// Unset placeholders render empty or with their defaults
+	if placeholders, err := kitGenerator.KitPlaceholders(kit); err == nil {
+		var missing []string
+		for _, name := range placeholders {
+			if _, ok := values[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			gl.Log("warn", fmt.Sprintf("Placeholders without a value: %s", strings.Join(missing, ", ")))
+		}
+	}
+
+	projectName := values["project_name"]
+	if projectName == "" {
+		projectName = kit.Name
+	}
+	req := &types.GenerationRequest{
+		KitName:      kit.Name,
+		ProjectName:  projectName,
+		OutputPath:   d.previewDir,
+		Placeholders: sortedPlaceholderValues(values),
+	}
+	kitFS := engine.DirFS(kit.LocalPath)
+	if err := kitGenerator.GenerateFromFS(kit, kitFS, req); err != nil {
+		if issue, ok := generator.TemplateIssue(kitFS, err); ok {
+			return errors.New(issue.String())
+		}
+		return err
+	}
+	if err := kitGenerator.SaveManifest(); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	gl.Log("info", fmt.Sprintf("✅ Rendered in %s", time.Since(start).Round(time.Millisecond)))
+	logIncrementalReport(kitGenerator.IncrementalReport())
+
+	if d.exec == "" {
+		return nil
+	}
+	gl.Log("info", fmt.Sprintf("▶️  %s", d.exec))
+	cmd := exec.Command("sh", "-c", d.exec)
+	cmd.Dir = d.previewDir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", d.exec, err)
+	}
+	return nil
+}
+
+// watch re-renders whenever a file of the kit or the values file changes,
+// until ctx is done
+func (d *kitDev) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := d.addWatches(watcher, d.kitPath); err != nil {
+		return err
+	}
+	// Editors often replace files, so the values file is watched through its directory
+	if d.valuesFile != "" {
+		if err := watcher.Add(filepath.Dir(d.valuesFile)); err != nil {
+			return fmt.Errorf("failed to watch values file: %w", err)
+		}
+	}
+	gl.Log("info", "👀 Watching for changes (Ctrl+C to stop)")
+
+	changes := make(chan string)
+	go func() {
+		defer close(changes)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Create) && d.relevant(event.Name) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						if err := d.addWatches(watcher, event.Name); err != nil {
+							gl.Log("warn", err.Error())
+						}
+					}
+				}
+				select {
+				case changes <- event.Name:
+				case <-ctx.Done():
+					return
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				gl.Log("warn", fmt.Sprintf("Watcher error: %v", err))
+			}
+		}
+	}()
+	return d.rebuildOnChange(ctx, changes)
+}
+
+// rebuildOnChange re-renders the preview once changes have been quiet for
+// kitDevDebounce. Paths that don't affect the preview are ignored. It returns
+// when ctx is done, or when changes is closed after rendering what is pending.
+func (d *kitDev) rebuildOnChange(ctx context.Context, changes <-chan string) error {
+	var rerender <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case path, ok := <-changes:
+			if !ok {
+				if rerender != nil {
+					d.rebuild()
+				}
+				return nil
+			}
+			if d.relevant(path) {
+				rerender = time.After(kitDevDebounce)
+			}
+
+		case <-rerender:
+			rerender = nil
+			d.rebuild()
+		}
+	}
+}
+
+// rebuild re-renders the preview; failures are logged so watching goes on
+func (d *kitDev) rebuild() {
+	if err := d.render(); err != nil {
+		gl.Log("error", err.Error())
+	}
+}
+
+// addWatches watches root and every directory below it, except the preview
+// directory and version control metadata
+func (d *kitDev) addWatches(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path == d.previewDir || entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// relevant reports whether a change to path affects the preview
+func (d *kitDev) relevant(path string) bool {
+	if path == d.valuesFile {
+		return true
+	}
+	return isWithin(d.kitPath, path) && !isWithin(d.previewDir, path)
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// loadDevValues reads a YAML mapping of placeholder values. Lists are joined
+// with commas, as foreach rules expect; an empty path yields no values.
+func loadDevValues(path string) (map[string]string, error) {
+	values := make(map[string]string)
+	if path == "" {
+		return values, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values file: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse values file %s: %w", path, err)
+	}
+
+	for name, value := range raw {
+		switch value := value.(type) {
+		case nil:
+			values[name] = ""
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
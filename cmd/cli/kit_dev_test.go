@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rafa-mori/gocrafter/internal/generator"
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// devKit sets up a kit under development, its values file and a preview
+// directory outside the kit
+func devKit(t *testing.T) *kitDev {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"kit/metadata.yaml":       "name: dev\ndescription: Kit under development\nplaceholders:\n  - project_name\n",
+		"kit/templates/README.md": "# {{project_name}}\n",
+		"values.yaml":             "project_name: demo\n",
+	}
+	for name, content := range files {
+		writeDevFile(t, filepath.Join(dir, name), content)
+	}
+
+	t.Setenv(generator.KitsPathEnv, "")
+	manager, err := generator.NewKitManager(&types.KitConfig{
+		KitsPath:  filepath.Join(dir, "kits"),
+		CachePath: filepath.Join(dir, "cache"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &kitDev{
+		manager:    manager,
+		kitPath:    filepath.Join(dir, "kit"),
+		valuesFile: filepath.Join(dir, "values.yaml"),
+		previewDir: filepath.Join(dir, "preview"),
+	}
+}
+
+func writeDevFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func previewFile(t *testing.T, d *kitDev, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(d.previewDir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// rebuildAfter feeds paths to rebuildOnChange as if they had changed, and
+// returns once it has rendered what is pending
+func rebuildAfter(t *testing.T, d *kitDev, paths ...string) {
+	t.Helper()
+	changes := make(chan string, len(paths))
+	for _, path := range paths {
+		changes <- path
+	}
+	close(changes)
+	if err := d.rebuildOnChange(context.Background(), changes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKitDevRebuild(t *testing.T) {
+	d := devKit(t)
+	if err := d.render(); err != nil {
+		t.Fatal(err)
+	}
+	if got := previewFile(t, d, "README.md"); got != "# demo\n" {
+		t.Fatalf("README.md = %q", got)
+	}
+
+	// Changes outside the kit, or to the preview itself, are ignored
+	writeDevFile(t, d.valuesFile, "project_name: renamed\n")
+	rebuildAfter(t, d, filepath.Join(filepath.Dir(d.kitPath), "other.yaml"), filepath.Join(d.previewDir, "README.md"))
+	if got := previewFile(t, d, "README.md"); got != "# demo\n" {
+		t.Errorf("README.md after unrelated changes = %q, want it untouched", got)
+	}
+
+	rebuildAfter(t, d, d.valuesFile)
+	if got := previewFile(t, d, "README.md"); got != "# renamed\n" {
+		t.Errorf("README.md after a values change = %q", got)
+	}
+
+	// A broken template is reported and the next change renders again
+	template := filepath.Join(d.kitPath, "templates", "README.md")
+	writeDevFile(t, template, "# {{project_name\n")
+	if err := d.render(); err == nil {
+		t.Error("render of a broken template succeeded")
+	}
+	rebuildAfter(t, d, template)
+	writeDevFile(t, template, "# {{project_name}}!\n")
+	writeDevFile(t, filepath.Join(d.kitPath, "templates", "docs", "NEW.md"), "new\n")
+	rebuildAfter(t, d, template, filepath.Join(d.kitPath, "templates", "docs"))
+	if got := previewFile(t, d, "README.md"); got != "# renamed!\n" {
+		t.Errorf("README.md after fixing the template = %q", got)
+	}
+	if got := previewFile(t, d, "docs/NEW.md"); got != "new\n" {
+		t.Errorf("docs/NEW.md = %q", got)
+	}
+}
+
+func TestKitDevRebuildDebounce(t *testing.T) {
+	d := devKit(t)
+	if err := d.render(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan string)
+	done := make(chan error)
+	go func() { done <- d.rebuildOnChange(ctx, changes) }()
+
+	// Nothing is rendered while changes keep coming
+	writeDevFile(t, d.valuesFile, "project_name: first\n")
+	changes <- d.valuesFile
+	writeDevFile(t, d.valuesFile, "project_name: second\n")
+	changes <- d.valuesFile
+	if got := previewFile(t, d, "README.md"); got != "# demo\n" {
+		t.Errorf("README.md before the debounce = %q", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for previewFile(t, d, "README.md") != "# second\n" {
+		if time.Now().After(deadline) {
+			t.Fatalf("README.md = %q, want the last values rendered", previewFile(t, d, "README.md"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/generator"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+)
+
+func kitSearchCommand() *cobra.Command {
+	var (
+		tags       []string
+		language   string
+		registries []string
+		offline    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search [terms...]",
+		Short: "Search the kit registries",
+		Long: `Search the registry indexes for kits.
+
+Registries are index files listing kits with their description, tags,
+language, versions and source, given as HTTP URLs or local paths with
+--registry or $GOCRAFTER_REGISTRIES (comma-separated). Kits matching every
+term are listed best match first: names weigh most, then tags, then
+descriptions. Downloaded indexes are cached, so searching works offline.`,
+		Example: `  # Search by name, tags and description
+  gocrafter kit search api
+
+  # Only Go kits tagged grpc
+  gocrafter kit search --tag grpc --language go
+
+  # Use the cached indexes only
+  gocrafter kit search worker --offline`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKitSearchCommand(args, tags, language, registries, offline)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Only list kits with this tag (repeatable)")
+	cmd.Flags().StringVarP(&language, "language", "l", "", "Only list kits for this language")
+	addRegistryFlags(cmd, &registries, &offline)
+	return cmd
+}
+
+// addRegistryFlags adds the flags selecting the registry indexes to read
+func addRegistryFlags(cmd *cobra.Command, registries *[]string, offline *bool) {
+	cmd.Flags().StringSliceVar(registries, "registry", nil, "Registry index URL or path (repeatable; default $"+generator.RegistriesEnv+")")
+	cmd.Flags().BoolVar(offline, "offline", false, "Serve registry indexes and kit downloads from the cache only")
+}
+
+// newRegistryKitManager returns a kit manager reading the given registries,
+// or the configured ones if none are given
+func newRegistryKitManager(registries []string, offline bool) (*generator.KitManagerImpl, error) {
+	kitManager, err := generator.NewKitManager(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize kit manager: %w", err)
+	}
+	if len(registries) > 0 {
+		kitManager.SetRegistries(registries)
+	}
+	kitManager.SetOffline(offline)
+	return kitManager, nil
+}
+
+func runKitSearchCommand(terms, tags []string, language string, registries []string, offline bool) error {
+	kitManager, err := newRegistryKitManager(registries, offline)
+	if err != nil {
+		return err
+	}
+
+	entries, err := kitManager.LoadIndex()
+	if err != nil {
+		return err
+	}
+
+	results := generator.SearchIndex(entries, terms, tags, language)
+	if len(results) == 0 {
+		gl.Log("info", "No kits found")
+		return nil
+	}
+
+	gl.Log("info", fmt.Sprintf("🔎 Kits found (%d):", len(results)))
+	gl.Log("info", "")
+	for _, entry := range results {
+		printIndexEntry(entry)
+	}
+	gl.Log("info", "Use 'gocrafter kit add <name>' to install a kit")
+	return nil
+}
+
+func printIndexEntry(entry generator.IndexEntry) {
+	if len(entry.Versions) > 0 {
+		gl.Log("info", fmt.Sprintf("📦 %s (%s)", entry.Name, entry.Versions[0]))
+	} else {
+		gl.Log("info", fmt.Sprintf("📦 %s", entry.Name))
+	}
+	if entry.Description != "" {
+		gl.Log("info", fmt.Sprintf("   %s", entry.Description))
+	}
+	if entry.Language != "" {
+		gl.Log("info", fmt.Sprintf("   Language: %s", entry.Language))
+	}
+	if len(entry.Tags) > 0 {
+		gl.Log("info", fmt.Sprintf("   Tags: %s", strings.Join(entry.Tags, ", ")))
+	}
+	gl.Log("info", fmt.Sprintf("   Source: %s", entry.Source))
+	gl.Log("info", "")
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rafa-mori/gocrafter/internal/license"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+)
+
+// LicenseCommand creates the license catalog command
+func LicenseCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "license",
+		Aliases: []string{"licenses"},
+		Short:   "Show the licenses gocrafter can generate",
+		Long: `Show the embedded license catalog. Any identifier or alias listed here can be
+passed to 'gocrafter new --license' or used as the value of a kit's license
+placeholder; the matching LICENSE file is written with the author and year.`,
+		Example: `  # List available licenses
+  gocrafter license list`,
+		Annotations: GetDescriptions([]string{"Show the licenses gocrafter can generate", "Show the embedded SPDX license catalog used to write LICENSE files."}, false),
+	}
+
+	cmd.AddCommand(licenseListCommand())
+
+	return cmd
+}
+
+func licenseListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls", "l"},
+		Short:   "List available licenses",
+		Long:    `List the licenses in the embedded catalog with their SPDX identifiers.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLicenseListCommand()
+		},
+	}
+}
+
+func runLicenseListCommand() error {
+	licenses := license.List()
+
+	gl.Log("info", fmt.Sprintf("📜 Licenses (%d):", len(licenses)))
+	for _, l := range licenses {
+		gl.Log("info", fmt.Sprintf("  %-14s %s", l.ID, l.Name))
+	}
+	gl.Log("info", "")
+	gl.Log("info", "Use 'gocrafter new <name> --license <id>' to pick one, or 'none' to skip LICENSE")
+
+	return nil
+}
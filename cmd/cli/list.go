@@ -2,15 +2,15 @@ package cli
 
 import (
 	"fmt"
-	"path/filepath"
 
-	"github.com/rafa-mori/gocrafter/internal/generator"
 	gl "github.com/rafa-mori/gocrafter/logger"
 	"github.com/spf13/cobra"
 )
 
 // ListCommand creates a command to list available templates
 func ListCommand() *cobra.Command {
+	var templatesDir string
+
 	cmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls", "templates"},
@@ -23,86 +23,46 @@ func ListCommand() *cobra.Command {
   gocrafter templates`,
 		Annotations: GetDescriptions([]string{"List available project templates", "List all available project templates with their descriptions."}, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runListCommand()
+			return runListCommand(templatesDir)
 		},
 	}
 
+	cmd.Flags().StringVar(&templatesDir, "templates-dir", "", "Directory of templates searched before the built-in ones (default $GOCRAFTER_TEMPLATES)")
+
 	return cmd
 }
 
-func runListCommand() error {
-	gl.Log("info", "🎯 Available Project Templates:")
-
-	templates := generator.SupportedTemplates()
-	templateDescriptions := map[string]string{
-		"api-rest":     "REST API server with HTTP endpoints (Gin/Fiber)",
-		"cli-tool":     "Command-line application with Cobra framework",
-		"microservice": "Microservice with gRPC and HTTP endpoints",
-		"grpc-service": "Pure gRPC service with protocol buffers",
-		"worker":       "Background worker for job processing",
-		"library":      "Go library/package for reusable code",
+func runListCommand(templatesDir string) error {
+	catalog, err := loadTemplateCatalog(templatesDir)
+	if err != nil {
+		return err
 	}
 
-	templateFeatures := map[string][]string{
-		"api-rest": {
-			"HTTP server with routing",
-			"Middleware support",
-			"Database integration",
-			"Authentication ready",
-			"API documentation",
-		},
-		"cli-tool": {
-			"Cobra CLI framework",
-			"Subcommands support",
-			"Configuration management",
-			"Logging system",
-			"Cross-platform builds",
-		},
-		"microservice": {
-			"gRPC and HTTP servers",
-			"Service discovery",
-			"Health checks",
-			"Metrics collection",
-			"Distributed tracing",
-		},
-		"grpc-service": {
-			"Protocol buffers",
-			"gRPC server",
-			"Client generation",
-			"Streaming support",
-			"Service mesh ready",
-		},
-		"worker": {
-			"Job queue integration",
-			"Background processing",
-			"Retry mechanisms",
-			"Monitoring hooks",
-			"Graceful shutdown",
-		},
-		"library": {
-			"Package structure",
-			"Documentation templates",
-			"Testing framework",
-			"CI/CD workflows",
-			"Version management",
-		},
-	}
+	gl.Log("info", "🎯 Available Project Templates:")
 
-	for _, tmpl := range templates {
-		desc := templateDescriptions[tmpl]
-		features := templateFeatures[tmpl]
+	if len(catalog.Templates) == 0 {
+		gl.Log("info", "No usable templates found")
+	}
 
-		gl.Log("info", fmt.Sprintf("📦 %s\n", tmpl))
-		gl.Log("info", fmt.Sprintf("   %s\n", desc))
+	for _, tmpl := range catalog.Templates {
+		gl.Log("info", fmt.Sprintf("📦 %s\n", tmpl.Name))
+		gl.Log("info", fmt.Sprintf("   %s\n", tmpl.Info.Description))
 
-		if len(features) > 0 {
+		if len(tmpl.Info.Features) > 0 {
 			gl.Log("info", "   Features:")
-			for _, feature := range features {
+			for _, feature := range tmpl.Info.Features {
 				gl.Log("info", fmt.Sprintf("   • %s\n", feature))
 			}
 		}
 	}
 
+	if len(catalog.Invalid) > 0 {
+		gl.Log("warn", fmt.Sprintf("⚠️  Invalid templates (%d):", len(catalog.Invalid)))
+		for _, invalid := range catalog.Invalid {
+			gl.Log("warn", fmt.Sprintf("   %s (%s): %v", invalid.Name, invalid.Path, invalid.Err))
+		}
+	}
+
 	gl.Log("info", fmt.Sprintf("💡 Usage:"))
 	gl.Log("info", fmt.Sprintf("   gocrafter new --template <template-name>"))
 	gl.Log("info", fmt.Sprintf("   gocrafter new  # Interactive mode"))
@@ -112,6 +72,8 @@ func runListCommand() error {
 
 // InfoCommand creates a command to show detailed information about a template
 func InfoCommand() *cobra.Command {
+	var templatesDir string
+
 	cmd := &cobra.Command{
 		Use:   "info <template-name>",
 		Short: "Show detailed information about a template",
@@ -124,39 +86,26 @@ func InfoCommand() *cobra.Command {
 		Args:        cobra.ExactArgs(1),
 		Annotations: GetDescriptions([]string{"Show detailed information about a template", "Show detailed information about a specific template including its structure and features."}, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInfoCommand(args[0])
+			return runInfoCommand(args[0], templatesDir)
 		},
 	}
 
+	cmd.Flags().StringVar(&templatesDir, "templates-dir", "", "Directory of templates searched before the built-in ones (default $GOCRAFTER_TEMPLATES)")
+
 	return cmd
 }
 
-func runInfoCommand(templateName string) error {
-	// Check if template exists
-	supportedTemplates := generator.SupportedTemplates()
-	templateExists := false
-	for _, tmpl := range supportedTemplates {
-		if tmpl == templateName {
-			templateExists = true
-			break
-		}
-	}
-
-	if !templateExists {
-		return fmt.Errorf("template '%s' not found. Run 'gocrafter list' to see available templates", templateName)
-	}
-
-	// Get templates path
-	templatesPath, err := getTemplatesPath()
+func runInfoCommand(templateName, templatesDir string) error {
+	catalog, err := loadTemplateCatalog(templatesDir)
 	if err != nil {
-		return fmt.Errorf("failed to get templates path: %w", err)
+		return err
 	}
 
-	// Get template info
-	info, err := generator.GetTemplateInfo(templatesPath, templateName)
+	tmpl, err := catalog.Get(templateName)
 	if err != nil {
-		return fmt.Errorf("failed to get template info: %w", err)
+		return err
 	}
+	info := tmpl.Info
 
 	// Display template information
 	gl.Log("info", fmt.Sprintf("📦 Template: %s\n", info.Name))
@@ -180,8 +129,7 @@ func runInfoCommand(templateName string) error {
 
 	// Show template structure
 	gl.Log("info", "\n📁 Template Structure:")
-	templatePath := filepath.Join(templatesPath, templateName)
-	if err := showTemplateStructure(templatePath, ""); err != nil {
+	if err := showTemplateStructure(tmpl.Path, ""); err != nil {
 		gl.Log("info", fmt.Sprintf("   (Unable to show structure: %s)\n", err))
 	}
 
@@ -4,24 +4,44 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	gocrafter "github.com/rafa-mori/gocrafter"
+	"github.com/rafa-mori/gocrafter/internal/engine"
 	"github.com/rafa-mori/gocrafter/internal/generator"
+	"github.com/rafa-mori/gocrafter/internal/license"
+	"github.com/rafa-mori/gocrafter/internal/profile"
 	"github.com/rafa-mori/gocrafter/internal/prompt"
 	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 	"github.com/spf13/cobra"
 )
 
+// newOptions holds the flags of the new and regen commands
+type newOptions struct {
+	template     string
+	templatesDir string
+	kit          string
+	outputDir    string
+	configFile   string
+	quick        bool
+	author       string
+	license      string
+	headers      bool
+	profile      string
+	post         generator.PostProcessOptions
+	jobs         int
+	incremental  generator.IncrementalOptions
+	git          *bool // nil leaves the decision to the kit default
+	gitOpts      generator.GitOptions
+}
+
 // NewCommand creates a new project generation command
 func NewCommand() *cobra.Command {
 	var (
-		template   string
-		kit        string
-		outputDir  string
-		configFile string
-		quick      bool
-		author     string
-		license    string
+		opts    newOptions
+		gitInit bool
 	)
 
 	cmd := &cobra.Command{
@@ -46,60 +66,267 @@ external project templates that can be added from repositories.`,
   gocrafter new --config project.json
 
   # Specify output directory and author
-  gocrafter new my-service --kit microservice --output /path/to/projects --author "John Doe"`,
+  gocrafter new my-service --kit microservice --output /path/to/projects --author "John Doe"
+
+  # Pre-fill answers from a saved profile
+  gocrafter new my-service --kit microservice --profile backend-team
+
+  # Use Apache-2.0 and add license headers to every Go file
+  gocrafter new my-service --kit microservice --license Apache-2.0 --license-headers
+
+  # Initialize a git repository with an initial commit and a remote
+  gocrafter new my-service --kit microservice --git --git-remote git@github.com:acme/my-service.git
+
+  # Regenerate an existing project, rewriting only files whose inputs changed
+  gocrafter new my-service --kit microservice --incremental --prune`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runNewCommand(args, template, kit, outputDir, configFile, quick, author, license)
+			if cmd.Flags().Changed("git") {
+				opts.git = &gitInit
+			}
+			return runNewCommand(args, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&template, "template", "t", "", "Built-in template to use (api-rest, cli-tool, microservice, etc.)")
-	cmd.Flags().StringVarP(&kit, "kit", "k", "", "Kit to use for project generation")
-	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Output directory for the new project")
-	cmd.Flags().StringVarP(&configFile, "config", "c", "", "Configuration file to use")
-	cmd.Flags().BoolVarP(&quick, "quick", "q", false, "Quick mode with minimal prompts")
-	cmd.Flags().StringVarP(&author, "author", "a", "", "Project author name")
-	cmd.Flags().StringVarP(&license, "license", "l", "MIT", "Project license")
+	cmd.Flags().StringVarP(&opts.template, "template", "t", "", "Template to use (see 'gocrafter list')")
+	cmd.Flags().StringVar(&opts.templatesDir, "templates-dir", "", "Directory of templates searched before the built-in ones (default $GOCRAFTER_TEMPLATES)")
+	cmd.Flags().StringVarP(&opts.kit, "kit", "k", "", "Kit to use for project generation")
+	cmd.Flags().StringVarP(&opts.outputDir, "output", "o", "", "Output directory for the new project")
+	cmd.Flags().StringVarP(&opts.configFile, "config", "c", "", "Configuration file to use")
+	cmd.Flags().BoolVarP(&opts.quick, "quick", "q", false, "Quick mode with minimal prompts")
+	cmd.Flags().StringVarP(&opts.author, "author", "a", "", "Project author name")
+	cmd.Flags().StringVarP(&opts.license, "license", "l", "", "Project license, see 'gocrafter license list' (defaults to the profile license or MIT; 'none' skips LICENSE)")
+	cmd.Flags().BoolVar(&opts.headers, "license-headers", false, "Add a copyright and SPDX header to every generated Go file")
+	cmd.Flags().StringVar(&opts.profile, "profile", "", "Answer profile to pre-fill values from (defaults to the active profile)")
+	cmd.Flags().BoolVar(&opts.post.SkipModInit, "no-mod-init", false, "Don't run go mod init when the template has no go.mod")
+	cmd.Flags().BoolVar(&opts.post.SkipFormat, "no-format", false, "Don't format generated Go files")
+	cmd.Flags().BoolVar(&opts.post.SkipTidy, "no-tidy", false, "Don't run go mod tidy on the generated project")
+	cmd.Flags().BoolVar(&opts.post.Offline, "offline", false, "Don't download modules (GOPROXY=off)")
+	cmd.Flags().IntVarP(&opts.jobs, "jobs", "j", 0, "Number of files rendered concurrently (default: number of CPUs)")
+	cmd.Flags().BoolVar(&opts.incremental.Enabled, "incremental", false, "Update an existing project, rewriting only files whose inputs changed")
+	cmd.Flags().BoolVar(&opts.incremental.Prune, "prune", false, "With --incremental, delete unmodified files that are no longer generated")
+	cmd.Flags().BoolVar(&opts.incremental.Force, "force", false, "With --incremental, overwrite files edited since they were generated")
+	cmd.Flags().BoolVar(&gitInit, "git", false, "Initialize a git repository with an initial commit (kits may enable this by default)")
+	cmd.Flags().StringVar(&opts.gitOpts.Branch, "git-branch", "", "Default branch name (defaults to the kit, profile or main)")
+	cmd.Flags().StringVar(&opts.gitOpts.Remote, "git-remote", "", "Remote URL to add as origin")
 
 	return cmd
 }
 
-func runNewCommand(args []string, template, kit, outputDir, configFile string, quick bool, author, license string) error {
+// RegenCommand creates the regen command: new with --incremental set
+func RegenCommand() *cobra.Command {
+	cmd := NewCommand()
+	cmd.Use = "regen [project-name]"
+	cmd.Short = "Regenerate an existing project, rewriting only what changed"
+	cmd.Long = `Regenerate a project from its template or kit into its existing directory.
+
+Files whose inputs (source file, values, partials, kit rules and functions)
+and contents are unchanged are skipped, so regen is cheap enough to run in a
+watch loop. Answers given when the project was generated are reused instead
+of prompting; profiles and flags still override them. Files that are no
+longer generated are reported, and deleted with --prune unless they were
+edited since. Files edited since they were generated are kept and reported;
+--force overwrites them.`
+	cmd.Example = `  # Pick up changes to a kit
+  gocrafter regen my-service --kit microservice
+
+  # Also delete files the kit no longer generates
+  gocrafter regen my-service --kit microservice --prune`
+
+	incremental := cmd.Flags().Lookup("incremental")
+	incremental.DefValue = "true"
+	_ = incremental.Value.Set("true")
+	return cmd
+}
+
+func runNewCommand(args []string, opts newOptions) error {
 	// Validate that both template and kit are not specified
-	if template != "" && kit != "" {
+	if opts.template != "" && opts.kit != "" {
 		return fmt.Errorf("cannot specify both template and kit. Use either --template or --kit")
 	}
 
+	// Fail fast on an unknown license before prompting
+	if opts.license != "" {
+		id, err := validateLicense(opts.license)
+		if err != nil {
+			return err
+		}
+		opts.license = id
+	}
+
+	// Resolve the answer profile (organization profile + named or active profile)
+	answers, err := resolveProfile(opts.profile)
+	if err != nil {
+		return err
+	}
+
+	// Commit as the given author, falling back to the profile
+	opts.gitOpts.AuthorName = opts.author
+	if answers != nil {
+		if opts.gitOpts.AuthorName == "" {
+			opts.gitOpts.AuthorName = answers.Author
+		}
+		opts.gitOpts.AuthorEmail = answers.Email
+		if opts.gitOpts.Branch == "" {
+			opts.gitOpts.Branch = answers.GitBranch
+		}
+	}
+
 	// If kit is specified, use kit generation
-	if kit != "" {
-		return runKitGeneration(args, kit, outputDir, author, license)
+	if opts.kit != "" {
+		return runKitGeneration(args, opts, answers)
 	}
 
 	// Otherwise, use traditional template generation
-	return runTemplateGeneration(args, template, outputDir, configFile, quick)
+	lic := generator.LicenseOptions{ID: opts.license, Holder: opts.author, GoHeaders: opts.headers}
+	if answers != nil {
+		if lic.ID == "" {
+			lic.ID = answers.License
+		}
+		if lic.Holder == "" {
+			lic.Holder = answers.Author
+		}
+	}
+	if lic.ID == "" {
+		lic.ID = "MIT"
+	}
+	id, err := validateLicense(lic.ID)
+	if err != nil {
+		return err
+	}
+	lic.ID = id
+
+	return runTemplateGeneration(args, opts, answers, lic)
 }
 
-func runKitGeneration(args []string, kitName, outputDir, author, license string) error {
+// kitPlaceholderValues returns the placeholder values known before
+// prompting: previous answers when regenerating, overridden by --author and
+// --license. Profile values fill the placeholders the kit doesn't ask for;
+// for the ones it does, the prompt offers them as defaults instead.
+func kitPlaceholderValues(required []string, answers *types.Profile, previous map[string]string, opts newOptions) []types.PlaceholderValue {
+	asked := make(map[string]bool, len(required))
+	for _, name := range required {
+		asked[name] = true
+	}
+
+	values := make(map[string]string)
+	for _, pv := range answers.PlaceholderValues() {
+		if !asked[pv.Name] {
+			values[pv.Name] = pv.Value
+		}
+	}
+	for name, value := range previous {
+		values[name] = value
+	}
+	if opts.author != "" {
+		values["author"] = opts.author
+	}
+	if opts.license != "" {
+		values["license"] = opts.license
+	}
+	if _, ok := answers.PlaceholderDefault("license"); !ok && values["license"] == "" {
+		values["license"] = "MIT"
+	}
+	return sortedPlaceholderValues(values)
+}
+
+// validateLicense resolves a license against the catalog; "none" is kept as is
+func validateLicense(id string) (string, error) {
+	if strings.EqualFold(strings.TrimSpace(id), "none") {
+		return "none", nil
+	}
+	return license.Validate(id)
+}
+
+// licenseHolder is the copyright holder for a project without a known author
+func licenseHolder(author, projectName string) string {
+	if author != "" {
+		return author
+	}
+	return fmt.Sprintf("The %s Authors", projectName)
+}
+
+// sortedPlaceholderValues returns values as placeholder values, sorted by name
+func sortedPlaceholderValues(values map[string]string) []types.PlaceholderValue {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]types.PlaceholderValue, 0, len(names))
+	for _, name := range names {
+		list = append(list, types.PlaceholderValue{Name: name, Value: values[name]})
+	}
+	return list
+}
+
+// isGitRepository reports whether dir already holds a git repository, as
+// projects being regenerated do
+func isGitRepository(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// logIncrementalReport lists the files an incremental generation wrote and removed
+func logIncrementalReport(report *engine.IncrementalReport) {
+	if report == nil {
+		return
+	}
+	for _, name := range report.Written {
+		gl.Log("info", fmt.Sprintf("  ✏️  %s", name))
+	}
+	for _, name := range report.Removed {
+		gl.Log("info", fmt.Sprintf("  🗑️  %s", name))
+	}
+}
+
+func resolveProfile(profileName string) (*types.Profile, error) {
+	store, err := profile.NewStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize profile store: %w", err)
+	}
+
+	answers, err := store.Resolve(profileName, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve profile: %w", err)
+	}
+
+	if answers != nil {
+		gl.Log("info", fmt.Sprintf("Using profile: %s", answers.Name))
+	}
+
+	return answers, nil
+}
+
+func runKitGeneration(args []string, opts newOptions, answers *types.Profile) error {
 	// Validate project name
 	if len(args) == 0 {
 		return fmt.Errorf("project name is required when using kit generation")
 	}
-	
+
 	projectName := args[0]
-	
+	kitName := opts.kit
+
 	// Initialize kit manager
 	kitManager, err := generator.NewKitManager(nil)
 	if err != nil {
 		return fmt.Errorf("failed to initialize kit manager: %w", err)
 	}
 
+	// Refresh the kit first if it is stale and auto_update is on
+	if !opts.post.Offline {
+		if _, err := kitManager.RefreshStaleKit(kitName); err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to update kit '%s', using the installed version: %v", kitName, err))
+		}
+	}
+
 	// Create kit generator
-	kitGenerator := generator.NewKitGenerator(kitManager)
+	kitGenerator := generator.NewKitGenerator(kitManager).WithPostProcess(opts.post).WithJobs(opts.jobs).WithIncremental(opts.incremental)
 
 	// Set output path
-	if outputDir == "" {
-		outputDir = filepath.Join(".", projectName)
-	} else {
-		outputDir = filepath.Join(outputDir, projectName)
+	outputDir := filepath.Join(".", projectName)
+	if opts.outputDir != "" {
+		outputDir = filepath.Join(opts.outputDir, projectName)
 	}
 
 	// Get kit placeholders
@@ -108,26 +335,18 @@ func runKitGeneration(args []string, kitName, outputDir, author, license string)
 		return fmt.Errorf("failed to get kit placeholders: %w", err)
 	}
 
-	// Create placeholder values
-	var placeholderValues []types.PlaceholderValue
-	
-	// Add basic placeholders
-	if author != "" {
-		placeholderValues = append(placeholderValues, types.PlaceholderValue{
-			Name:  "author",
-			Value: author,
-		})
-	}
-	
-	if license != "" {
-		placeholderValues = append(placeholderValues, types.PlaceholderValue{
-			Name:  "license",
-			Value: license,
-		})
+	// Regenerating reuses the answers the project was generated with
+	var previous map[string]string
+	if opts.incremental.Enabled {
+		if previous, err = kitGenerator.PreviousAnswers(outputDir); err != nil {
+			return fmt.Errorf("failed to load previous answers: %w", err)
+		}
 	}
 
+	placeholderValues := kitPlaceholderValues(placeholders, answers, previous, opts)
+
 	// Prompt for additional placeholders
-	prompter := prompt.NewKitPrompt()
+	prompter := prompt.NewKitPromptWithProfile(answers)
 	additionalPlaceholders, err := prompter.PromptForPlaceholders(placeholders, placeholderValues)
 	if err != nil {
 		return fmt.Errorf("failed to prompt for placeholders: %w", err)
@@ -136,6 +355,25 @@ func runKitGeneration(args []string, kitName, outputDir, author, license string)
 	// Merge placeholders
 	placeholderValues = append(placeholderValues, additionalPlaceholders...)
 
+	// Validate the license placeholder against the catalog, whatever its source
+	lic := generator.LicenseOptions{Holder: opts.author, GoHeaders: opts.headers}
+	for i, pv := range placeholderValues {
+		switch pv.Name {
+		case "license":
+			id, err := validateLicense(pv.Value)
+			if err != nil {
+				return err
+			}
+			placeholderValues[i].Value = id
+			lic.ID = id
+		case "author":
+			if lic.Holder == "" {
+				lic.Holder = pv.Value
+			}
+		}
+	}
+	lic.Holder = licenseHolder(lic.Holder, projectName)
+
 	// Create generation request
 	req := &types.GenerationRequest{
 		KitName:      kitName,
@@ -154,10 +392,47 @@ func runKitGeneration(args []string, kitName, outputDir, author, license string)
 		return fmt.Errorf("kit generation failed: %w", err)
 	}
 
+	kit, err := kitManager.GetKit(kitName)
+	if err != nil {
+		return fmt.Errorf("failed to get kit: %w", err)
+	}
+
+	// Write LICENSE unless the kit rendered one
+	lic.GoHeaders = lic.GoHeaders || kit.LicenseHeaders
+	if err := generator.ApplyLicense(outputDir, lic); err != nil {
+		return fmt.Errorf("failed to apply license: %w", err)
+	}
+	if err := kitGenerator.SaveManifest(); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	// Initialize the git repository, defaulting to the kit's setting
+	if opts.git == nil && kit.Git != nil {
+		opts.git = &kit.Git.Init
+	}
+	if opts.git != nil && *opts.git && !isGitRepository(outputDir) {
+		if opts.gitOpts.Branch == "" && kit.Git != nil {
+			opts.gitOpts.Branch = kit.Git.Branch
+		}
+		opts.gitOpts.HooksDir = filepath.Join(kit.LocalPath, generator.KitHooksDir)
+		opts.gitOpts.Source = fmt.Sprintf("kit %s %s", kit.Name, kit.Version)
+		if err := generator.InitGitRepository(outputDir, opts.gitOpts); err != nil {
+			return fmt.Errorf("failed to initialize git repository: %w", err)
+		}
+	}
+
 	// Success message
 	gl.Log("info", "✅ Project generated successfully from kit!")
 	gl.Log("info", fmt.Sprintf("📁 Location: %s", outputDir))
 	gl.Log("info", fmt.Sprintf("📦 Kit: %s", kitName))
+	logIncrementalReport(kitGenerator.IncrementalReport())
+	for _, result := range kitGenerator.PostProcessResults() {
+		level := "info"
+		if result.Status == generator.ProcessorFailed {
+			level = "warn"
+		}
+		gl.Log(level, fmt.Sprintf("🔧 %s", result))
+	}
 	gl.Log("info", "Next steps:")
 	gl.Log("info", fmt.Sprintf("  cd %s", projectName))
 	gl.Log("info", "  # Check the generated README.md for specific instructions")
@@ -165,21 +440,33 @@ func runKitGeneration(args []string, kitName, outputDir, author, license string)
 	return nil
 }
 
-func runTemplateGeneration(args []string, template, outputDir, configFile string, quick bool) error {
+func runTemplateGeneration(args []string, opts newOptions, answers *types.Profile, lic generator.LicenseOptions) error {
 	var config *generator.ProjectConfig
-	var err error
+	template := opts.template
+
+	catalog, err := loadTemplateCatalog(opts.templatesDir)
+	if err != nil {
+		return err
+	}
+
+	// Fail fast on an unknown or invalid template before prompting
+	if template != "" {
+		if _, err := catalog.Get(template); err != nil {
+			return err
+		}
+	}
 
 	// Load from config file if provided
-	if configFile != "" {
-		gl.Log("info", fmt.Sprintf("Loading configuration from file: %s", configFile))
+	if opts.configFile != "" {
+		gl.Log("info", fmt.Sprintf("Loading configuration from file: %s", opts.configFile))
 		// TODO: Implement config file loading
 		return fmt.Errorf("config file loading not yet implemented")
 	}
 
 	// Quick mode
-	if quick && template != "" {
+	if opts.quick && template != "" {
 		gl.Log("info", fmt.Sprintf("Running in quick mode with template: %s", template))
-		config, err = prompt.QuickPrompt(template)
+		config, err = prompt.QuickPrompt(template, answers)
 		if err != nil {
 			return fmt.Errorf("quick prompt failed: %w", err)
 		}
@@ -188,11 +475,14 @@ func runTemplateGeneration(args []string, template, outputDir, configFile string
 		config = generator.NewProjectConfig()
 		config.Name = args[0]
 		config.Template = template
-		config.Module = fmt.Sprintf("github.com/user/%s", args[0]) // Default module name
+		config.ApplyProfile(answers)
+		if config.Module == "" {
+			config.Module = fmt.Sprintf("github.com/user/%s", args[0]) // Default module name
+		}
 	} else {
 		// Interactive mode
 		gl.Log("info", "Running interactive mode")
-		prompter := prompt.NewInteractivePrompt()
+		prompter := prompt.NewInteractivePrompt().WithProfile(answers).WithTemplates(catalog.Templates)
 		config, err = prompter.Run()
 		if err != nil {
 			return fmt.Errorf("interactive prompt failed: %w", err)
@@ -200,8 +490,8 @@ func runTemplateGeneration(args []string, template, outputDir, configFile string
 	}
 
 	// Set output directory if provided
-	if outputDir != "" {
-		config.OutputDir = outputDir
+	if opts.outputDir != "" {
+		config.OutputDir = opts.outputDir
 	}
 
 	// Validate configuration
@@ -209,21 +499,39 @@ func runTemplateGeneration(args []string, template, outputDir, configFile string
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Get templates path
-	templatesPath, err := getTemplatesPath()
+	// Resolve the template, refusing invalid ones
+	tmpl, err := catalog.Get(config.Template)
 	if err != nil {
-		return fmt.Errorf("failed to get templates path: %w", err)
+		return err
 	}
 
 	// Create generator and generate project
-	gen := generator.NewGenerator(config, templatesPath)
+	gen := generator.NewGenerator(config, tmpl.Root.FS).WithPostProcess(opts.post).WithJobs(opts.jobs).WithIncremental(opts.incremental)
 	if err := gen.Generate(); err != nil {
 		return fmt.Errorf("project generation failed: %w", err)
 	}
 
+	// Write LICENSE unless the template rendered one
+	lic.Holder = licenseHolder(lic.Holder, config.Name)
+	if err := generator.ApplyLicense(config.GetOutputPath(), lic); err != nil {
+		return fmt.Errorf("failed to apply license: %w", err)
+	}
+	if err := gen.SaveManifest(); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	// Initialize the git repository
+	if opts.git != nil && *opts.git && !isGitRepository(config.GetOutputPath()) {
+		opts.gitOpts.Source = fmt.Sprintf("template %s", config.Template)
+		if err := generator.InitGitRepository(config.GetOutputPath(), opts.gitOpts); err != nil {
+			return fmt.Errorf("failed to initialize git repository: %w", err)
+		}
+	}
+
 	// Success message
 	gl.Log("info", "✅ Project generated successfully!")
 	gl.Log("info", fmt.Sprintf("📁 Location: %s", config.GetOutputPath()))
+	logIncrementalReport(gen.IncrementalReport())
 	gl.Log("info", "Next steps:")
 	gl.Log("info", fmt.Sprintf("  cd %s", config.Name))
 	gl.Log("info", "  make run    # Start the application")
@@ -233,30 +541,41 @@ func runTemplateGeneration(args []string, template, outputDir, configFile string
 	return nil
 }
 
-func getTemplatesPath() (string, error) {
-	// Try to find templates directory relative to the executable
-	// First, try relative to current working directory
-	if _, err := os.Stat("templates"); err == nil {
-		return "templates", nil
+// TemplatesEnv names directories (separated like PATH) searched for templates
+// before the built-in ones when --templates-dir isn't given
+const TemplatesEnv = "GOCRAFTER_TEMPLATES"
+
+// getTemplateRoots returns the template roots in precedence order: the
+// --templates-dir directory or $GOCRAFTER_TEMPLATES, then the templates
+// embedded in the binary
+func getTemplateRoots(templatesDir string) ([]generator.TemplateRoot, error) {
+	var dirs []string
+	if templatesDir != "" {
+		dirs = []string{templatesDir}
+	} else {
+		dirs = filepath.SplitList(os.Getenv(TemplatesEnv))
 	}
 
-	// Try relative to the executable location
-	executable, err := os.Executable()
-	if err != nil {
-		return "", err
+	var roots []generator.TemplateRoot
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("templates directory not found: %s", dir)
+		}
+		roots = append(roots, generator.TemplateRoot{Name: dir, FS: engine.DirFS(dir)})
 	}
 
-	execDir := filepath.Dir(executable)
-	templatesPath := filepath.Join(execDir, "templates")
-	if _, err := os.Stat(templatesPath); err == nil {
-		return templatesPath, nil
-	}
+	return append(roots, generator.TemplateRoot{Name: "built-in", FS: gocrafter.Templates()}), nil
+}
 
-	// Try one level up from executable (for development)
-	templatesPath = filepath.Join(execDir, "..", "templates")
-	if _, err := os.Stat(templatesPath); err == nil {
-		return templatesPath, nil
+// loadTemplateCatalog scans the template roots for templates
+func loadTemplateCatalog(templatesDir string) (*generator.TemplateCatalog, error) {
+	roots, err := getTemplateRoots(templatesDir)
+	if err != nil {
+		return nil, err
 	}
-
-	return "", fmt.Errorf("templates directory not found")
+	return generator.DiscoverTemplates(roots), nil
 }
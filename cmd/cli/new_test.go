@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+func TestKitPlaceholderValues(t *testing.T) {
+	profile := &types.Profile{
+		Author:       "Ana Lima",
+		Email:        "ana@example.com",
+		License:      "Apache-2.0",
+		Placeholders: map[string]string{"port": "9090"},
+	}
+
+	tests := []struct {
+		name     string
+		required []string
+		profile  *types.Profile
+		previous map[string]string
+		opts     newOptions
+		want     map[string]string
+	}{
+		{
+			// Placeholders the kit asks for are left to the prompt, which
+			// offers the profile values as defaults
+			name:     "profile",
+			required: []string{"author", "port"},
+			profile:  profile,
+			want:     map[string]string{"email": "ana@example.com", "license": "Apache-2.0"},
+		},
+		{
+			name:     "previous answers",
+			required: []string{"author", "port"},
+			profile:  profile,
+			previous: map[string]string{"port": "8000", "license": "MIT"},
+			want:     map[string]string{"email": "ana@example.com", "license": "MIT", "port": "8000"},
+		},
+		{
+			name:     "flags",
+			required: []string{"author", "license"},
+			profile:  profile,
+			previous: map[string]string{"author": "Old"},
+			opts:     newOptions{author: "Bo", license: "BSD-3-Clause"},
+			want:     map[string]string{"author": "Bo", "email": "ana@example.com", "license": "BSD-3-Clause", "port": "9090"},
+		},
+		{
+			name:     "no profile",
+			required: []string{"author", "license"},
+			want:     map[string]string{"license": "MIT"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := make(map[string]string)
+		for _, pv := range kitPlaceholderValues(tt.required, tt.profile, tt.previous, tt.opts) {
+			got[pv.Name] = pv.Value
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: kitPlaceholderValues = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rafa-mori/gocrafter/internal/profile"
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileCommand creates the answer profile management command
+func ProfileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles"},
+		Short:   "Manage answer profiles",
+		Long: `Manage reusable answer profiles that pre-fill author, license, organization,
+registry and other values when generating projects.
+
+Profiles are stored in ~/.gocrafter/profiles/<name>.yaml. An organization-wide
+profile is also picked up from the nearest .gocrafter/profile.yaml found walking
+up from the current directory; personal profiles override its values.`,
+		Example: `  # List all profiles
+  gocrafter profile list
+
+  # Set values on a profile (created if missing)
+  gocrafter profile set backend-team author "Jane Doe"
+  gocrafter profile set backend-team placeholders.go_version 1.24
+
+  # Use a profile by default
+  gocrafter profile use backend-team
+
+  # Show the effective profile
+  gocrafter profile show`,
+		Annotations: GetDescriptions([]string{"Manage answer profiles", "Manage reusable answer profiles that pre-fill values when generating projects."}, false),
+	}
+
+	cmd.AddCommand(
+		profileListCommand(),
+		profileShowCommand(),
+		profileSetCommand(),
+		profileUseCommand(),
+	)
+
+	return cmd
+}
+
+func profileListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls", "l"},
+		Short:   "List all profiles",
+		Long:    `List all saved answer profiles and the organization profile in effect.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileListCommand()
+		},
+	}
+}
+
+func profileShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [profile-name]",
+		Short: "Show a profile",
+		Long:  `Show a saved profile, or the effective profile (organization + active) when no name is given.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return runProfileShowCommand(name)
+		},
+	}
+}
+
+func profileSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <profile-name> <key> <value>",
+		Short: "Set a profile value",
+		Long: `Set a value on a profile, creating the profile if it does not exist.
+
+Supported keys: description, author, email, license, organization, registry,
+module_prefix, git_branch, placeholders.<name>, project.database, project.cache,
+project.queue, project.ci, project.monitoring, project.features (comma-separated),
+project.docker, project.kubernetes and project.custom.<name>.`,
+		Args: cobra.ExactArgs(3),
+		Example: `  gocrafter profile set backend-team license Apache-2.0
+  gocrafter profile set backend-team project.features "Health Checks,Metrics (Prometheus)"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileSetCommand(args[0], args[1], args[2])
+		},
+	}
+}
+
+func profileUseCommand() *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "use <profile-name>",
+		Short: "Use a profile by default",
+		Long:  `Select the profile applied to every generation that does not pass --profile.`,
+		Args:  cobra.RangeArgs(0, 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clear {
+				return runProfileUseCommand("")
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("profile name is required (or use --clear)")
+			}
+			return runProfileUseCommand(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "Stop using a default profile")
+	return cmd
+}
+
+// Command implementations
+
+func runProfileListCommand() error {
+	store, err := profile.NewStore()
+	if err != nil {
+		return err
+	}
+
+	names, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	if orgPath := profile.FindOrgProfile(cwd); orgPath != "" {
+		gl.Log("info", fmt.Sprintf("🏢 Organization profile: %s", orgPath))
+		gl.Log("info", "")
+	}
+
+	if len(names) == 0 {
+		gl.Log("info", "No profiles saved")
+		gl.Log("info", "Use 'gocrafter profile set <name> <key> <value>' to create one")
+		return nil
+	}
+
+	active := store.Active()
+	gl.Log("info", fmt.Sprintf("👤 Profiles (%d):", len(names)))
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		gl.Log("info", fmt.Sprintf(" %s %s", marker, name))
+	}
+
+	return nil
+}
+
+func runProfileShowCommand(name string) error {
+	store, err := profile.NewStore()
+	if err != nil {
+		return err
+	}
+
+	var p *types.Profile
+	if name != "" {
+		p, err = store.Load(name)
+	} else {
+		p, err = store.Resolve("", ".")
+	}
+	if err != nil {
+		return err
+	}
+
+	if p == nil {
+		gl.Log("info", "No profile in effect")
+		return nil
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	if p.Source != "" {
+		gl.Log("info", fmt.Sprintf("# %s", p.Source))
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+func runProfileSetCommand(name, key, value string) error {
+	store, err := profile.NewStore()
+	if err != nil {
+		return err
+	}
+
+	p, err := store.Load(name)
+	if errors.Is(err, profile.ErrProfileNotFound) {
+		p = &types.Profile{Name: name}
+	} else if err != nil {
+		return err
+	}
+
+	if err := profile.SetField(p, key, value); err != nil {
+		return err
+	}
+
+	if err := store.Save(p); err != nil {
+		return err
+	}
+
+	gl.Log("info", fmt.Sprintf("Profile '%s' updated: %s = %s", name, key, value))
+	return nil
+}
+
+func runProfileUseCommand(name string) error {
+	store, err := profile.NewStore()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Use(name); err != nil {
+		return err
+	}
+
+	if name == "" {
+		gl.Log("info", "Default profile cleared")
+	} else {
+		gl.Log("info", fmt.Sprintf("Now using profile '%s'", name))
+	}
+	return nil
+}
@@ -0,0 +1,400 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FileEnv names a config file used instead of the default one
+	FileEnv = "GOCRAFTER_CONFIG"
+	// RegistriesEnv overrides the registries setting with comma-separated locations
+	RegistriesEnv = "GOCRAFTER_REGISTRIES"
+	// fileName is the name of the config file in the config directory
+	fileName = "config.yaml"
+)
+
+// Where a setting's value comes from
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// Setting is the effective value of a configuration key
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // SourceDefault, SourceFile or SourceEnv
+	Env    string // Environment variable overriding the key
+}
+
+// setting describes a configuration key
+type setting struct {
+	key  string
+	env  string
+	kind string // "path", "bool", "int" or "list"
+}
+
+// settings are the configuration keys, named like the KitConfig YAML fields
+var settings = []setting{
+	{key: "kits_path", env: "GOCRAFTER_KITS_DIR", kind: "path"},
+	{key: "cache_path", env: "GOCRAFTER_CACHE_DIR", kind: "path"},
+	{key: "auto_update", env: "GOCRAFTER_AUTO_UPDATE", kind: "bool"},
+	{key: "max_cache_age_days", env: "GOCRAFTER_MAX_CACHE_AGE_DAYS", kind: "int"},
+	{key: "max_cache_size_mb", env: "GOCRAFTER_MAX_CACHE_SIZE_MB", kind: "int"},
+	{key: "registries", env: RegistriesEnv, kind: "list"},
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *types.KitConfig
+	loadErr  error
+)
+
+// Keys returns the configuration keys
+func Keys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	return keys
+}
+
+// Path returns the config file: $GOCRAFTER_CONFIG, else config.yaml in
+// $XDG_CONFIG_HOME/gocrafter when XDG_CONFIG_HOME is set, else in ~/.gocrafter
+func Path() (string, error) {
+	if path := os.Getenv(FileEnv); path != "" {
+		return path, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gocrafter", fileName), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".gocrafter", fileName), nil
+}
+
+// Default returns the configuration used when no file or environment
+// variable sets a value. Kits honor $XDG_DATA_HOME and the cache
+// $XDG_CACHE_HOME.
+func Default() (*types.KitConfig, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	kitsPath := filepath.Join(homeDir, ".gocrafter", "kits")
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		kitsPath = filepath.Join(dir, "gocrafter", "kits")
+	}
+	cachePath := filepath.Join(homeDir, ".gocrafter", "cache")
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		cachePath = filepath.Join(dir, "gocrafter")
+	}
+	return &types.KitConfig{
+		KitsPath:     kitsPath,
+		CachePath:    cachePath,
+		AutoUpdate:   false,
+		MaxCacheAge:  7,
+		MaxCacheSize: 512,
+	}, nil
+}
+
+// Load returns the configuration: the defaults, overridden by the config
+// file, overridden by environment variables. It is read once per process;
+// every call returns a copy.
+func Load() (*types.KitConfig, error) {
+	loadOnce.Do(func() {
+		loaded, loadErr = load()
+	})
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	config := *loaded
+	config.Registries = append([]string(nil), loaded.Registries...)
+	return &config, nil
+}
+
+func load() (*types.KitConfig, error) {
+	config, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	file, path, err := readFile()
+	if err != nil {
+		return nil, err
+	}
+	// Paths in the file may start with ~ too
+	for _, s := range settings {
+		if value, ok := file[s.key].(string); ok && s.kind == "path" {
+			if file[s.key], err = s.parse(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := apply(config, file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := check(config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	env := make(map[string]interface{})
+	for _, s := range settings {
+		raw, ok := os.LookupEnv(s.env)
+		if !ok || raw == "" {
+			continue
+		}
+		value, err := s.parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $%s: %w", s.env, err)
+		}
+		env[s.key] = value
+	}
+	if err := apply(config, env); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// List returns the effective value of every key and where it comes from
+func List() ([]Setting, error) {
+	config, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	file, _, err := readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := toMap(config)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Setting, len(settings))
+	for i, s := range settings {
+		list[i] = Setting{Key: s.key, Value: format(values[s.key]), Source: SourceDefault, Env: s.env}
+		if _, ok := file[s.key]; ok {
+			list[i].Source = SourceFile
+		}
+		if os.Getenv(s.env) != "" {
+			list[i].Source = SourceEnv
+		}
+	}
+	return list, nil
+}
+
+// Get returns the effective value of key
+func Get(key string) (Setting, error) {
+	if _, err := lookup(key); err != nil {
+		return Setting{}, err
+	}
+	list, err := List()
+	if err != nil {
+		return Setting{}, err
+	}
+	for _, s := range list {
+		if s.Key == key {
+			return s, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("unknown config key '%s'", key)
+}
+
+// Set stores value for key in the config file, creating it if needed. Lists
+// are comma-separated; an empty value removes the key, restoring its default.
+func Set(key, value string) error {
+	s, err := lookup(key)
+	if err != nil {
+		return err
+	}
+
+	file, path, err := readFile()
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		delete(file, key)
+	} else {
+		parsed, err := s.parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		file[key] = parsed
+	}
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that the config file at path parses and holds valid values
+func Validate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	file := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	var unknown []string
+	for key := range file {
+		if _, err := lookup(key); err != nil {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown config keys: %s", strings.Join(unknown, ", "))
+	}
+	config := &types.KitConfig{}
+	if err := apply(config, file); err != nil {
+		return err
+	}
+	return check(config)
+}
+
+// readFile returns the keys set in the config file and its path; a missing
+// file sets none
+func readFile() (map[string]interface{}, string, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, "", err
+	}
+
+	file := make(map[string]interface{})
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, path, nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, "", fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if file == nil {
+		file = make(map[string]interface{})
+	}
+	return file, path, nil
+}
+
+// apply sets the keys in values on config, through its YAML fields
+func apply(config *types.KitConfig, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, config)
+}
+
+// check rejects values that parse but make no sense
+func check(config *types.KitConfig) error {
+	if config.MaxCacheAge < 0 {
+		return fmt.Errorf("max_cache_age_days must not be negative")
+	}
+	if config.MaxCacheSize < 0 {
+		return fmt.Errorf("max_cache_size_mb must not be negative")
+	}
+	return nil
+}
+
+// toMap returns the keys of config as YAML values
+func toMap(config *types.KitConfig) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func lookup(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown config key '%s' (known keys: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// parse converts a command line or environment value to the key's type
+func (s setting) parse(value string) (interface{}, error) {
+	switch s.kind {
+	case "bool":
+		return strconv.ParseBool(value)
+	case "int":
+		n, err := strconv.Atoi(value)
+		if err == nil && n < 0 {
+			err = fmt.Errorf("must not be negative")
+		}
+		return n, err
+	case "list":
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	case "path":
+		if value == "~" || strings.HasPrefix(value, "~/") {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			value = filepath.Join(homeDir, strings.TrimPrefix(value, "~"))
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// format renders a YAML value as set takes it
+func format(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// isolate points every location config reads at a fresh directory and
+// forgets the memoized configuration
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{FileEnv, "XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME"} {
+		t.Setenv(name, "")
+	}
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+	}
+	reload()
+	t.Cleanup(reload)
+	return home
+}
+
+// reload makes the next Load read the files and environment again
+func reload() {
+	loadOnce = sync.Once{}
+	loaded, loadErr = nil, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	home := isolate(t)
+
+	if path, _ := Path(); path != filepath.Join(home, ".gocrafter", "config.yaml") {
+		t.Errorf("Path = %q, want the home config", path)
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if path, _ := Path(); path != filepath.Join("/xdg", "gocrafter", "config.yaml") {
+		t.Errorf("Path with XDG_CONFIG_HOME = %q", path)
+	}
+	t.Setenv(FileEnv, "/etc/gocrafter.yaml")
+	if path, _ := Path(); path != "/etc/gocrafter.yaml" {
+		t.Errorf("Path with %s = %q", FileEnv, path)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	home := isolate(t)
+
+	config, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.KitsPath != filepath.Join(home, ".gocrafter", "kits") || config.CachePath != filepath.Join(home, ".gocrafter", "cache") {
+		t.Errorf("Default = kits %q, cache %q; want both in ~/.gocrafter", config.KitsPath, config.CachePath)
+	}
+
+	t.Setenv("XDG_DATA_HOME", "/xdg-data")
+	t.Setenv("XDG_CACHE_HOME", "/xdg-cache")
+	if config, err = Default(); err != nil {
+		t.Fatal(err)
+	}
+	if config.KitsPath != filepath.Join("/xdg-data", "gocrafter", "kits") {
+		t.Errorf("kits_path with XDG_DATA_HOME = %q", config.KitsPath)
+	}
+	if config.CachePath != filepath.Join("/xdg-cache", "gocrafter") {
+		t.Errorf("cache_path with XDG_CACHE_HOME = %q", config.CachePath)
+	}
+
+	// A configured kits_path still wins
+	writeFile(t, filepath.Join(home, ".gocrafter", "config.yaml"), "kits_path: ~/kits\n")
+	if config, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if config.KitsPath != filepath.Join(home, "kits") {
+		t.Errorf("kits_path from the file = %q", config.KitsPath)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	home := isolate(t)
+
+	config, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxCacheAge != 7 || config.AutoUpdate || config.CachePath != filepath.Join(home, ".gocrafter", "cache") {
+		t.Errorf("defaults = %+v", config)
+	}
+
+	// The home file sets values; an XDG file replaces it entirely
+	writeFile(t, filepath.Join(home, ".gocrafter", "config.yaml"), "max_cache_age_days: 3\nauto_update: true\n")
+	reload()
+	if config, _ = Load(); config.MaxCacheAge != 3 || !config.AutoUpdate {
+		t.Errorf("home file: %+v", config)
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "xdg-cache"))
+	writeFile(t, filepath.Join(xdg, "gocrafter", "config.yaml"), "max_cache_age_days: 5\nkits_path: ~/kits\nregistries: [a, b]\n")
+	reload()
+	config, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxCacheAge != 5 || config.AutoUpdate {
+		t.Errorf("XDG file: %+v, want it to replace the home file", config)
+	}
+	if config.KitsPath != filepath.Join(home, "kits") {
+		t.Errorf("kits_path = %q, want ~ expanded", config.KitsPath)
+	}
+	if config.CachePath != filepath.Join(home, "xdg-cache", "gocrafter") {
+		t.Errorf("cache_path = %q, want the XDG cache", config.CachePath)
+	}
+
+	// Environment variables override the file
+	t.Setenv("GOCRAFTER_MAX_CACHE_AGE_DAYS", "9")
+	t.Setenv(RegistriesEnv, "c, d")
+	reload()
+	if config, _ = Load(); config.MaxCacheAge != 9 || !reflect.DeepEqual(config.Registries, []string{"c", "d"}) {
+		t.Errorf("env: %+v", config)
+	}
+
+	list, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := make(map[string]string)
+	for _, s := range list {
+		sources[s.Key] = s.Source
+	}
+	want := map[string]string{
+		"kits_path":          SourceFile,
+		"cache_path":         SourceDefault,
+		"auto_update":        SourceDefault,
+		"max_cache_age_days": SourceEnv,
+		"max_cache_size_mb":  SourceDefault,
+		"registries":         SourceEnv,
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	for _, tt := range []struct{ file, env, value string }{
+		{file: "max_cache_age_days: -1\n"},
+		{file: "max_cache_size_mb: -5\n"},
+		{file: "auto_update: maybe\n"},
+		{env: "GOCRAFTER_MAX_CACHE_AGE_DAYS", value: "-1"},
+		{env: "GOCRAFTER_AUTO_UPDATE", value: "maybe"},
+	} {
+		home := isolate(t)
+		if tt.file != "" {
+			writeFile(t, filepath.Join(home, ".gocrafter", "config.yaml"), tt.file)
+		}
+		if tt.env != "" {
+			t.Setenv(tt.env, tt.value)
+		}
+		if config, err := Load(); err == nil {
+			t.Errorf("Load with %q %s=%q = %+v, want an error", tt.file, tt.env, tt.value, config)
+		}
+	}
+}
+
+func TestSetAndValidate(t *testing.T) {
+	home := isolate(t)
+	path := filepath.Join(home, ".gocrafter", "config.yaml")
+
+	if err := Set("max_cache_age_days", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("registries", "a,b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("registries", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(path); err != nil {
+		t.Errorf("Validate after Set: %v", err)
+	}
+	file, _, err := readFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(file, map[string]interface{}{"max_cache_age_days": 0}) {
+		t.Errorf("file = %v", file)
+	}
+
+	for _, bad := range [][2]string{{"nope", "1"}, {"max_cache_age_days", "-1"}, {"auto_update", "sometimes"}} {
+		if err := Set(bad[0], bad[1]); err == nil {
+			t.Errorf("Set(%q, %q) succeeded", bad[0], bad[1])
+		}
+	}
+
+	writeFile(t, path, "unknown_key: 1\n")
+	if err := Validate(path); err == nil {
+		t.Error("Validate accepted an unknown key")
+	}
+	writeFile(t, path, "max_cache_size_mb: -1\n")
+	if err := Validate(path); err == nil {
+		t.Error("Validate accepted a negative size")
+	}
+}
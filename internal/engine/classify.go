@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// templateSuffixes mark files that are always rendered; the suffix is
+// stripped from the generated file name
+var templateSuffixes = []string{".tmpl", ".tpl"}
+
+// defaultCopyGlobs are text files that must never be rendered
+var defaultCopyGlobs = []string{"go.sum", "*.sum"}
+
+// sniffLen is how much of a file is inspected to detect binary content
+const sniffLen = 8000
+
+// FileClassifier decides whether a template file is rendered or copied as-is.
+// Decisions are made in this order:
+//  1. a .tmpl/.tpl suffix always renders (and is stripped from the output name)
+//  2. files matching a copy glob are copied verbatim
+//  3. files matching a render glob are rendered
+//  4. otherwise the content is sniffed and only text is rendered
+type FileClassifier struct {
+	render []string
+	copy   []string
+}
+
+// NewFileClassifier creates a classifier with the given render and copy globs.
+// Globs are matched against the path relative to the templates root.
+func NewFileClassifier(render, copy []string) *FileClassifier {
+	return &FileClassifier{
+		render: render,
+		copy:   append(append([]string{}, defaultCopyGlobs...), copy...),
+	}
+}
+
+// Classify reports whether the file at relPath should be rendered and the
+// relative path it should be written to
+func (c *FileClassifier) Classify(relPath string, content []byte) (render bool, outPath string) {
+	for _, suffix := range templateSuffixes {
+		if strings.HasSuffix(relPath, suffix) && len(relPath) > len(suffix) {
+			return true, strings.TrimSuffix(relPath, suffix)
+		}
+	}
+
+	if MatchAnyGlob(c.copy, relPath) {
+		return false, relPath
+	}
+	if MatchAnyGlob(c.render, relPath) {
+		return true, relPath
+	}
+
+	return !IsBinary(content), relPath
+}
+
+// IsBinary reports whether content looks like binary data: a NUL byte or
+// invalid UTF-8 within the first few kilobytes. Real binary formats (images,
+// archives, executables) fail one of the two checks almost immediately.
+func IsBinary(content []byte) bool {
+	sample := content
+	if len(sample) > sniffLen {
+		sample = sample[:sniffLen]
+		// Drop a multi-byte rune cut off at the end of the sample
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	return !utf8.Valid(sample)
+}
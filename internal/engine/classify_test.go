@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"testing"
+)
+
+// pngHeader is the start of a real PNG file: the signature and an IHDR chunk
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func TestClassify(t *testing.T) {
+	c := NewFileClassifier([]string{"assets/*.svg", "**/*.bin"}, []string{"docs/**", "*.md"})
+	text := []byte("# {{project_name}}\n")
+
+	tests := []struct {
+		relPath string
+		content []byte
+		render  bool
+		outPath string
+	}{
+		{"main.go", text, true, "main.go"},
+		{"Dockerfile.tmpl", text, true, "Dockerfile"},
+		{"config/app.yaml.tpl", text, true, "config/app.yaml"},
+		// A suffix alone is not a template name, and beats copy globs
+		{".tmpl", text, true, ".tmpl"},
+		{"README.md.tmpl", text, true, "README.md"},
+		// Copy-only files are never rendered, even when they look like text
+		{"go.sum", text, false, "go.sum"},
+		{"tools/go.work.sum", text, false, "tools/go.work.sum"},
+		{"README.md", text, false, "README.md"},
+		{"docs/guide.txt", text, false, "docs/guide.txt"},
+		// Content is sniffed, whatever the name says
+		{"license.png", pngHeader, false, "license.png"},
+		{"LICENSE", pngHeader, false, "LICENSE"},
+		{"notes.png", text, true, "notes.png"},
+		// Render globs override sniffing
+		{"assets/logo.svg", pngHeader, true, "assets/logo.svg"},
+		{"data/blob.bin", []byte("\x00\x01"), true, "data/blob.bin"},
+		{"data/blob.dat", []byte("\x00\x01"), false, "data/blob.dat"},
+	}
+
+	for _, tt := range tests {
+		render, outPath := c.Classify(tt.relPath, tt.content)
+		if render != tt.render || outPath != tt.outPath {
+			t.Errorf("Classify(%q) = %v, %q; want %v, %q", tt.relPath, render, outPath, tt.render, tt.outPath)
+		}
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	// A multi-byte rune cut off by the sniff window is still text
+	long := append(bytes.Repeat([]byte("a"), sniffLen-1), "é"...)
+
+	tests := []struct {
+		name    string
+		content []byte
+		binary  bool
+	}{
+		{"empty", nil, false},
+		{"text", []byte("package main\n"), false},
+		{"utf-8", []byte("olá, 世界"), false},
+		{"rune across the sniff window", long, false},
+		{"png", pngHeader, true},
+		{"nul byte", []byte("text\x00more"), true},
+		{"invalid utf-8", []byte("caf\xe9"), true},
+		{"nul after the sniff window", append(bytes.Repeat([]byte("a"), sniffLen), 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsBinary(tt.content); got != tt.binary {
+			t.Errorf("IsBinary(%s) = %v, want %v", tt.name, got, tt.binary)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Condition is a parsed boolean expression over placeholder values, e.g.
+// `cache != "" && database == "postgres"`. Supported syntax: identifiers,
+// quoted strings, true/false, ==, !=, !, &&, ||, and/or/not and parentheses.
+// An identifier on its own is true when its value is set and not "false",
+// "0", "no" or "off". Undefined identifiers evaluate to the empty string.
+type Condition struct {
+	source string
+	root   conditionNode
+	idents []string
+}
+
+// Lookup resolves an identifier used in a condition
+type Lookup func(name string) (string, bool)
+
+// ParseCondition parses a condition expression
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+
+	return &Condition{source: expr, root: root, idents: p.idents}, nil
+}
+
+// String returns the original expression
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Identifiers returns the names referenced by the condition
+func (c *Condition) Identifiers() []string {
+	return c.idents
+}
+
+// Eval evaluates the condition against the given values
+func (c *Condition) Eval(lookup Lookup) bool {
+	return c.root.eval(lookup)
+}
+
+// FileRules includes or excludes template paths based on conditions keyed by glob
+type FileRules struct {
+	globs      []string
+	conditions map[string]*Condition
+}
+
+// NewFileRules parses a glob → condition map from kit or template metadata
+func NewFileRules(rules map[string]string) (*FileRules, error) {
+	fr := &FileRules{conditions: make(map[string]*Condition)}
+	for glob, expr := range rules {
+		cond, err := ParseCondition(expr)
+		if err != nil {
+			return nil, fmt.Errorf("conditions[%s]: %w", glob, err)
+		}
+		fr.globs = append(fr.globs, glob)
+		fr.conditions[glob] = cond
+	}
+	sort.Strings(fr.globs)
+	return fr, nil
+}
+
+// Include reports whether relPath should be generated; every rule whose glob
+// matches the path must hold
+func (fr *FileRules) Include(relPath string, lookup Lookup) bool {
+	if fr == nil {
+		return true
+	}
+	for _, glob := range fr.globs {
+		if MatchGlob(glob, relPath) && !fr.conditions[glob].Eval(lookup) {
+			return false
+		}
+	}
+	return true
+}
+
+// Identifiers returns the sorted names referenced by any rule
+func (fr *FileRules) Identifiers() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, glob := range fr.globs {
+		for _, name := range fr.conditions[glob].Identifiers() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasEmptySegment reports whether a rendered relative path has a segment
+// that rendered to nothing, which means the file or directory is skipped
+func hasEmptySegment(relPath string) bool {
+	for _, segment := range strings.Split(relPath, "/") {
+		if strings.TrimSpace(segment) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func truthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no", "off":
+		return false
+	}
+	return true
+}
+
+// Expression tree
+
+type conditionNode interface {
+	eval(lookup Lookup) bool
+}
+
+type operand struct {
+	literal bool
+	value   string
+}
+
+func (o operand) resolve(lookup Lookup) string {
+	if o.literal {
+		return o.value
+	}
+	value, _ := lookup(o.value)
+	return value
+}
+
+type truthNode struct{ operand operand }
+
+func (n truthNode) eval(lookup Lookup) bool { return truthy(n.operand.resolve(lookup)) }
+
+type compareNode struct {
+	left, right operand
+	equal       bool
+}
+
+func (n compareNode) eval(lookup Lookup) bool {
+	return (n.left.resolve(lookup) == n.right.resolve(lookup)) == n.equal
+}
+
+type notNode struct{ inner conditionNode }
+
+func (n notNode) eval(lookup Lookup) bool { return !n.inner.eval(lookup) }
+
+type logicNode struct {
+	left, right conditionNode
+	and         bool
+}
+
+func (n logicNode) eval(lookup Lookup) bool {
+	if n.and {
+		return n.left.eval(lookup) && n.right.eval(lookup)
+	}
+	return n.left.eval(lookup) || n.right.eval(lookup)
+}
+
+// Tokenizer
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokOp
+)
+
+type conditionToken struct {
+	kind tokenKind
+	text string
+}
+
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	var tokens []conditionToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, conditionToken{tokString, string(runes[i+1 : end])})
+			i = end + 1
+		case r == '(' || r == ')':
+			tokens = append(tokens, conditionToken{tokOp, string(r)})
+			i++
+		case strings.ContainsRune("=!&|", r):
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				if pair == "==" || pair == "!=" || pair == "&&" || pair == "||" {
+					tokens = append(tokens, conditionToken{tokOp, pair})
+					i += 2
+					continue
+				}
+			}
+			if r != '!' {
+				return nil, fmt.Errorf("unexpected %q", string(r))
+			}
+			tokens = append(tokens, conditionToken{tokOp, "!"})
+			i++
+		case r == '.' || r == '_' || unicode.IsLetter(r):
+			end := i
+			for end < len(runes) && (runes[end] == '.' || runes[end] == '_' || runes[end] == '-' ||
+				unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			word := strings.TrimPrefix(string(runes[i:end]), ".")
+			switch word {
+			case "and":
+				tokens = append(tokens, conditionToken{tokOp, "&&"})
+			case "or":
+				tokens = append(tokens, conditionToken{tokOp, "||"})
+			case "not":
+				tokens = append(tokens, conditionToken{tokOp, "!"})
+			default:
+				tokens = append(tokens, conditionToken{tokIdent, word})
+			}
+			i = end
+		case unicode.IsDigit(r):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, conditionToken{tokString, string(runes[i:end])})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected %q", string(r))
+		}
+	}
+
+	return tokens, nil
+}
+
+// Parser
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+	idents []string
+}
+
+func (p *conditionParser) peekOp(op string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokOp && p.tokens[p.pos].text == op
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicNode{left: left, right: right, and: true}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.peekOp("!") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	}
+
+	if p.peekOp("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOp(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekOp("==") || p.peekOp("!=") {
+		equal := p.tokens[p.pos].text == "=="
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{left: left, right: right, equal: equal}, nil
+	}
+	return truthNode{operand: left}, nil
+}
+
+func (p *conditionParser) parseOperand() (operand, error) {
+	if p.pos >= len(p.tokens) {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	tok := p.tokens[p.pos]
+	switch tok.kind {
+	case tokString:
+		p.pos++
+		return operand{literal: true, value: tok.text}, nil
+	case tokIdent:
+		p.pos++
+		if tok.text == "true" || tok.text == "false" {
+			return operand{literal: true, value: tok.text}, nil
+		}
+		p.idents = append(p.idents, tok.text)
+		return operand{value: tok.text}, nil
+	}
+	return operand{}, fmt.Errorf("unexpected %q", tok.text)
+}
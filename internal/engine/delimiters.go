@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// DefaultDelimiters are the standard text/template action delimiters
+var DefaultDelimiters = types.Delimiters{Left: "{{", Right: "}}"}
+
+// rawSentinel marks a protected raw block while the content is rendered.
+// NUL bytes never appear in delimiters, so the sentinel passes through untouched.
+const rawSentinel = "\x00gocrafter-raw-%d\x00"
+
+// normalizeDelimiters fills missing delimiters with the defaults
+func normalizeDelimiters(d types.Delimiters) types.Delimiters {
+	if d.Left == "" {
+		d.Left = DefaultDelimiters.Left
+	}
+	if d.Right == "" {
+		d.Right = DefaultDelimiters.Right
+	}
+	return d
+}
+
+// ValidateDelimiters checks kit-declared delimiters for obvious mistakes
+func ValidateDelimiters(kit *types.Kit) error {
+	check := func(where string, d types.Delimiters) error {
+		if (d.Left == "") != (d.Right == "") {
+			return fmt.Errorf("%s: both left and right delimiters must be set", where)
+		}
+		if d.Left != "" && d.Left == d.Right {
+			return fmt.Errorf("%s: left and right delimiters must differ", where)
+		}
+		return nil
+	}
+
+	if kit.Delimiters != nil {
+		if err := check("delimiters", *kit.Delimiters); err != nil {
+			return err
+		}
+	}
+	for _, fd := range kit.FileDelimiters {
+		if fd.Glob == "" {
+			return fmt.Errorf("file_delimiters: glob is required")
+		}
+		if err := check(fmt.Sprintf("file_delimiters[%s]", fd.Glob), fd.Delimiters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DelimitersFor returns the delimiters for a template file, honoring
+// per-file overrides before the kit-wide setting
+func DelimitersFor(kit *types.Kit, relPath string) types.Delimiters {
+	for _, fd := range kit.FileDelimiters {
+		if MatchGlob(fd.Glob, relPath) {
+			return normalizeDelimiters(fd.Delimiters)
+		}
+	}
+	if kit.Delimiters != nil {
+		return normalizeDelimiters(*kit.Delimiters)
+	}
+	return DefaultDelimiters
+}
+
+// rawBlockPattern matches <left>raw<right> ... <left>endraw<right> blocks
+func rawBlockPattern(d types.Delimiters) *regexp.Regexp {
+	return delimPattern("raw", d, func(left, right string) string {
+		return `(?s)` + left + `-?\s*raw\s*-?` + right + `(.*?)` + left + `-?\s*endraw\s*-?` + right
+	})
+}
+
+// protectRawBlocks replaces verbatim blocks with sentinels and returns the
+// protected content together with the original block bodies
+func protectRawBlocks(content string, d types.Delimiters) (string, []string) {
+	var blocks []string
+	re := rawBlockPattern(d)
+	protected := re.ReplaceAllStringFunc(content, func(match string) string {
+		body := re.FindStringSubmatch(match)[1]
+		blocks = append(blocks, body)
+		return fmt.Sprintf(rawSentinel, len(blocks)-1)
+	})
+	return protected, blocks
+}
+
+// restoreRawBlocks puts the verbatim block bodies back in place of their sentinels
+func restoreRawBlocks(content string, blocks []string) string {
+	for i, body := range blocks {
+		content = strings.Replace(content, fmt.Sprintf(rawSentinel, i), body, 1)
+	}
+	return content
+}
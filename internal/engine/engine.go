@@ -0,0 +1,509 @@
+// Package engine renders projects from kits and built-in templates. Template
+// data comes from pluggable providers, so every source gets the same
+// functions, file rules and error handling.
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/rafa-mori/gocrafter/internal/funcs"
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// DataProvider supplies the values and functions templates render with
+type DataProvider interface {
+	// Values returns top-level template values, available as {{.name}} and
+	// as the {{name}} shorthand
+	Values() map[string]any
+	// Funcs returns template functions bound to the provider's data
+	Funcs() template.FuncMap
+}
+
+// Placeholders provides flat string values, such as kit placeholder answers
+type Placeholders map[string]string
+
+// Values returns the placeholders as template values
+func (p Placeholders) Values() map[string]any {
+	values := make(map[string]any, len(p))
+	for name, value := range p {
+		values[name] = value
+	}
+	return values
+}
+
+// Funcs returns no functions
+func (p Placeholders) Funcs() template.FuncMap {
+	return nil
+}
+
+// Source describes the files a project is generated from
+type Source struct {
+	FS       fs.FS
+	Dir      string     // Directory within FS whose contents are generated
+	Partials string     // Directory within FS holding partials; empty for none
+	Skip     []string   // Paths relative to Dir that are never generated
+	Kit      *types.Kit // Generation rules: delimiters, render/copy, conditions, foreach and modes
+
+	// Lenient keeps files that fail to parse or execute, with only the
+	// {{name}} shorthand applied, instead of failing generation
+	Lenient bool
+
+	// Fingerprint identifies inputs outside FS that affect rendering, such as
+	// the scripts behind kit functions; incremental generation re-renders
+	// every file when it changes
+	Fingerprint string
+}
+
+// Engine renders sources with the data of its providers
+type Engine struct {
+	data     map[string]any
+	funcs    template.FuncMap
+	partials Partials
+	delims   types.Delimiters
+	lenient  bool
+	jobs     int
+	inc      *incremental // Set by SetIncremental
+
+	mu    sync.Mutex
+	bases map[baseKey]*parsed  // Functions and partials, parsed once per delimiters
+	cache map[parseKey]*parsed // Parsed files and path segments
+}
+
+// New creates an engine with the shared function library and the given
+// providers; values of later providers override earlier ones
+func New(providers ...DataProvider) *Engine {
+	e := &Engine{
+		data:   make(map[string]any),
+		funcs:  funcs.FuncMap(),
+		delims: DefaultDelimiters,
+	}
+	e.funcs["include"] = includePlaceholder
+
+	for _, p := range providers {
+		e.AddProvider(p)
+	}
+	return e
+}
+
+// AddProvider merges a provider's values and functions into the engine
+func (e *Engine) AddProvider(p DataProvider) {
+	for name, value := range p.Values() {
+		e.data[name] = value
+	}
+	e.AddFuncs(p.Funcs())
+}
+
+// SetValue sets a single template value
+func (e *Engine) SetValue(name string, value any) {
+	e.data[name] = value
+	e.resetCache()
+}
+
+// Value returns a template value formatted as a string, as used by file
+// conditions, foreach lists and the {{name}} shorthand
+func (e *Engine) Value(name string) (string, bool) {
+	return lookupValue(e.data, name)
+}
+
+// AddFuncs registers additional template functions
+func (e *Engine) AddFuncs(fm template.FuncMap) {
+	for name, fn := range fm {
+		e.funcs[name] = fn
+	}
+	e.resetCache()
+}
+
+// Funcs returns a copy of the template functions available to templates
+func (e *Engine) Funcs() template.FuncMap {
+	fm := make(template.FuncMap, len(e.funcs))
+	for name, fn := range e.funcs {
+		fm[name] = fn
+	}
+	return fm
+}
+
+// SetJobs sets how many files Generate renders and writes concurrently;
+// n <= 0 uses one worker per CPU
+func (e *Engine) SetJobs(n int) {
+	e.jobs = n
+}
+
+// fileTask is a file found by the walk phase of Generate
+type fileTask struct {
+	name    string // Path within the source filesystem
+	relPath string // Path relative to the source directory
+	mode    os.FileMode
+	list    string // Foreach list the file is rendered for, if any
+}
+
+// Generate renders every file of src into outputPath. The source is walked
+// first, creating symlinks and kept empty directories; files are then
+// rendered and written by a bounded pool of workers. The error reported is
+// the one a sequential run would have hit first.
+func (e *Engine) Generate(src Source, outputPath string) error {
+	kit := src.Kit
+	if kit == nil {
+		kit = &types.Kit{}
+	}
+
+	if err := ValidateDelimiters(kit); err != nil {
+		return fmt.Errorf("invalid delimiters: %w", err)
+	}
+	if err := ValidateModes(kit); err != nil {
+		return fmt.Errorf("invalid modes: %w", err)
+	}
+	rules, err := NewFileRules(kit.Conditions)
+	if err != nil {
+		return fmt.Errorf("invalid conditions: %w", err)
+	}
+	classifier := NewFileClassifier(kit.Render, kit.Copy)
+
+	// Load shared partials
+	e.partials = nil
+	if src.Partials != "" {
+		if e.partials, err = LoadPartials(src.FS, src.Partials); err != nil {
+			return fmt.Errorf("failed to load partials: %w", err)
+		}
+	}
+	e.delims = DelimitersFor(kit, "")
+	e.lenient = src.Lenient
+	e.resetCache()
+	if e.inc != nil {
+		e.inc.start(e, src, kit)
+	}
+
+	// Walk phase: create symlinks and kept directories, collect the files to render
+	var files []fileTask
+	err = fs.WalkDir(src.FS, src.Dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Get relative path from the source root
+		relPath := relPath(src.Dir, name)
+		if relPath == "." {
+			return nil
+		}
+
+		// Partials and metadata living next to the files are never generated
+		if containsPath(src.Skip, relPath) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// Skip files and directories whose conditions don't hold
+		if !rules.Include(relPath, e.Value) {
+			gl.Log("debug", fmt.Sprintf("Skipping %s: condition not met", relPath))
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			// Directories are created with their first file, so excluded
+			// subtrees leave nothing behind. A segment that renders empty
+			// skips the whole directory.
+			processedPath, err := e.RenderPath(relPath)
+			if err != nil {
+				return err
+			}
+			if hasEmptySegment(processedPath) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// Keep markers only exist so the empty directory ships with the source
+		if keepFiles[d.Name()] {
+			processedPath, err := e.RenderPath(path.Dir(relPath))
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(filepath.Join(outputPath, filepath.FromSlash(processedPath)), 0755)
+		}
+
+		// Recreate symlinks instead of following them
+		if isSymlink(d) {
+			processedPath, err := e.RenderPath(relPath)
+			if err != nil {
+				return err
+			}
+			targetPath := filepath.Join(outputPath, filepath.FromSlash(processedPath))
+			err = createSymlink(src.FS, name, targetPath, outputPath, func(target string) string {
+				if rendered, err := e.RenderPath(target); err == nil {
+					return rendered
+				}
+				return target
+			})
+			if err == nil && e.inc != nil {
+				err = e.inc.recordLink(processedPath, targetPath)
+			}
+			return err
+		}
+
+		sourceMode, err := sourceFileMode(d)
+		if err != nil {
+			return err
+		}
+
+		list, _ := kitLoopFor(kit, relPath)
+		files = append(files, fileTask{
+			name:    name,
+			relPath: relPath,
+			mode:    kitModeFor(kit, relPath, sourceMode),
+			list:    list,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Render phase
+	err = e.renderFiles(files, func(f fileTask) error {
+		return e.generateFile(src.FS, kit, classifier, f, outputPath)
+	})
+	if err != nil || e.inc == nil {
+		return err
+	}
+	return e.inc.finish(outputPath)
+}
+
+// renderFiles runs render for every file on a bounded pool of workers. Once a
+// file fails, later files are skipped, and the failure of the earliest file
+// is returned regardless of the order workers finish in.
+func (e *Engine) renderFiles(files []fileTask, render func(fileTask) error) error {
+	jobs := e.jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(files) {
+		jobs = len(files)
+	}
+
+	errs := make([]error, len(files))
+	var firstFailed atomic.Int64
+	firstFailed.Store(int64(len(files)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if int64(i) > firstFailed.Load() {
+					continue
+				}
+				if err := render(files[i]); err != nil {
+					errs[i] = err
+					for failed := firstFailed.Load(); int64(i) < failed && !firstFailed.CompareAndSwap(failed, int64(i)); {
+						failed = firstFailed.Load()
+					}
+				}
+			}
+		}()
+	}
+	for i := range files {
+		if int64(i) > firstFailed.Load() {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateFile renders and writes one file, once per element of its foreach
+// list if it has one
+func (e *Engine) generateFile(fsys fs.FS, kit *types.Kit, classifier *FileClassifier, f fileTask, outputPath string) error {
+	content, err := fs.ReadFile(fsys, f.name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.relPath, err)
+	}
+
+	if f.list == "" {
+		return e.writeFile(kit, classifier, content, f.relPath, outputPath, f.mode, e.data)
+	}
+
+	// Files matched by a foreach rule are rendered once per list element
+	value, _ := e.Value(f.list)
+	items := ParseList(value)
+	if len(items) == 0 {
+		gl.Log("debug", fmt.Sprintf("Skipping %s: list '%s' is empty", f.relPath, f.list))
+	}
+	for i, item := range items {
+		if err := e.writeFile(kit, classifier, content, f.relPath, outputPath, f.mode, e.loopData(item, i)); err != nil {
+			return fmt.Errorf("%s[%d]: %w", f.list, i, err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) writeFile(kit *types.Kit, classifier *FileClassifier, content []byte, relPath, outputPath string, mode os.FileMode, data map[string]any) error {
+	// Text files are rendered; binary files are copied as-is
+	render, outPath := classifier.Classify(relPath, content)
+
+	// Files whose name renders to an empty string are skipped
+	processedPath, err := e.renderPath(outPath, data)
+	if err != nil {
+		return err
+	}
+	if hasEmptySegment(processedPath) {
+		gl.Log("debug", fmt.Sprintf("Skipping %s: path renders empty", relPath))
+		return nil
+	}
+	targetPath := filepath.Join(outputPath, filepath.FromSlash(processedPath))
+
+	// Incremental generation keeps files whose inputs and contents are
+	// unchanged, and files edited since they were generated
+	var input string
+	if e.inc != nil {
+		input = e.inc.inputHash(relPath, content, mode, data)
+		if e.inc.keep(processedPath, input, targetPath) {
+			return nil
+		}
+	}
+
+	if render {
+		rendered, err := e.render(relPath, string(content), DelimitersFor(kit, relPath), data)
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", relPath, err)
+		}
+		content = []byte(rendered)
+	}
+
+	var entry ManifestEntry
+	if e.inc != nil {
+		// Rewriting identical contents would only touch the file's mtime
+		entry = ManifestEntry{Input: input, Output: hashContent(content)}
+		if output, err := hashOutput(targetPath); err == nil && output == entry.Output {
+			e.inc.record(processedPath, entry, false)
+			return os.Chmod(targetPath, mode)
+		}
+	}
+
+	// Write target file
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+	if err := WriteFileMode(targetPath, content, mode); err != nil {
+		return fmt.Errorf("failed to write target file: %w", err)
+	}
+	if e.inc != nil {
+		e.inc.record(processedPath, entry, true)
+	}
+
+	gl.Log("debug", fmt.Sprintf("Generated file: %s", targetPath))
+	return nil
+}
+
+// Render renders content named name (used in error messages) with the given
+// delimiters. Blocks wrapped in <left>raw<right> ... <left>endraw<right> are
+// emitted verbatim.
+//
+// Values are looked up by the template itself, never substituted into the
+// source, so a value containing delimiters is output as-is. The {{name}}
+// shorthand is a field lookup of the top-level value name, wherever it is
+// used (see shorthand.resolve in shorthand.go).
+func (e *Engine) Render(name, content string, delims types.Delimiters) (string, error) {
+	return e.render(name, content, delims, e.data)
+}
+
+// RenderPath renders a slash-separated path. Each segment is rendered on its
+// own, so a segment may use conditionals (a segment that renders to nothing
+// makes the engine skip the file). Both the source and the default
+// delimiters are recognized in paths; a segment is rendered once, with the
+// first set it uses.
+func (e *Engine) RenderPath(p string) (string, error) {
+	return e.renderPath(p, e.data)
+}
+
+func (e *Engine) renderPath(p string, data map[string]any) (string, error) {
+	delimsList := []types.Delimiters{e.delims}
+	if e.delims != DefaultDelimiters {
+		delimsList = append(delimsList, DefaultDelimiters)
+	}
+
+	segments := strings.Split(filepath.ToSlash(p), "/")
+	for i, segment := range segments {
+		for _, delims := range delimsList {
+			if !strings.Contains(segment, delims.Left) {
+				continue
+			}
+			rendered, err := e.render(p, segment, delims, data)
+			if err != nil {
+				return "", fmt.Errorf("failed to render path %s: %w", p, err)
+			}
+			segments[i] = rendered
+			break
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// loopData returns the engine's values with item and index set for one
+// element of a foreach list
+func (e *Engine) loopData(item string, index int) map[string]any {
+	data := make(map[string]any, len(e.data)+2)
+	for name, value := range e.data {
+		data[name] = value
+	}
+	data[LoopItemPlaceholder] = item
+	data[LoopIndexPlaceholder] = strconv.Itoa(index)
+	return data
+}
+
+// lookupValue returns a value of data formatted as a string
+func lookupValue(data map[string]any, name string) (string, bool) {
+	value, ok := data[name]
+	if !ok {
+		return "", false
+	}
+	return formatValue(value), true
+}
+
+// formatValue formats a template value for conditions, lists and paths
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func containsPath(paths []string, p string) bool {
+	for _, candidate := range paths {
+		if candidate == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,584 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// braceSeeds are values that would be template code if they were re-evaluated
+var braceSeeds = []string{
+	"",
+	"plain",
+	"{{",
+	"}}",
+	"{{project_name}}",
+	"{{.project_name}}",
+	"{{ env \"HOME\" }}",
+	"{{template \"x\"}}",
+	"{{define \"x\"}}y{{end}}",
+	"{{raw}}{{endraw}}",
+	"}}{{",
+	"{{{{}}}}",
+	"{{- /* comment */ -}}",
+	"[[name]]",
+}
+
+func FuzzRenderValue(f *testing.F) {
+	for _, seed := range braceSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		for _, lenient := range []bool{false, true} {
+			e := New(Placeholders{"name": value, "project_name": "demo", "names": value})
+			e.lenient = lenient
+
+			templates := map[string]string{
+				"a{{name}}b":                        "a" + value + "b",
+				"a{{.name}}b":                       "a" + value + "b",
+				"{{names}}-{{name}}":                value + "-" + value,
+				"{{if name}}{{name}}{{end}}":        value,
+				"{{name | printf \"%s\"}}":          value,
+				"{{range list 1 2}}{{name}}{{end}}": value + value,
+			}
+			for source, want := range templates {
+				got, err := e.Render("fuzz", source, DefaultDelimiters)
+				if err != nil {
+					t.Fatalf("Render(%q) with name=%q: %v", source, value, err)
+				}
+				if got != want {
+					t.Fatalf("Render(%q) with name=%q = %q, want %q", source, value, got, want)
+				}
+			}
+		}
+	})
+}
+
+func FuzzRenderValueCustomDelimiters(f *testing.F) {
+	for _, seed := range braceSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		e := New(Placeholders{"name": value})
+		delims := DefaultDelimiters
+		delims.Left, delims.Right = "[[", "]]"
+
+		got, err := e.Render("fuzz", "{{keep}} [[name]] [[.name]]", delims)
+		if err != nil {
+			t.Fatalf("Render with name=%q: %v", value, err)
+		}
+		if want := "{{keep}} " + value + " " + value; got != want {
+			t.Fatalf("Render with name=%q = %q, want %q", value, got, want)
+		}
+	})
+}
+
+func FuzzRenderPath(f *testing.F) {
+	for _, seed := range braceSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		if strings.Contains(value, "/") {
+			t.Skip("values are path segments")
+		}
+		e := New(Placeholders{"name": value})
+
+		got, err := e.RenderPath("cmd/{{name}}/{{.name}}_test.go")
+		if err != nil {
+			t.Fatalf("RenderPath with name=%q: %v", value, err)
+		}
+		if want := "cmd/" + value + "/" + value + "_test.go"; got != want {
+			t.Fatalf("RenderPath with name=%q = %q, want %q", value, got, want)
+		}
+	})
+}
+
+func FuzzLenientFallback(f *testing.F) {
+	for _, seed := range braceSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		e := New(Placeholders{"name": value, "other": "x"})
+		e.lenient = true
+
+		// ${{ secrets.TOKEN }} doesn't parse, so only the shorthand is substituted
+		source := "${{ secrets.TOKEN }} {{name}} {{other}}"
+		got, err := e.Render("fuzz", source, DefaultDelimiters)
+		if err != nil {
+			t.Fatalf("Render with name=%q: %v", value, err)
+		}
+		if want := "${{ secrets.TOKEN }} " + value + " x"; got != want {
+			t.Fatalf("Render with name=%q = %q, want %q", value, got, want)
+		}
+	})
+}
+
+// benchmarkSource is a kit shaped like a large monorepo: many files using
+// functions, partials and the shorthand, some repeated per list element
+func benchmarkSource(files int) Source {
+	fsys := fstest.MapFS{
+		"partials/header.tmpl": {Data: []byte("// Code for {{project_name}} ({{.module}})\n")},
+	}
+	body := `{{template "header" .}}
+package {{snake .project_name}}
+
+{{range $i, $s := list "alpha" "beta" "gamma" "delta"}}
+// {{$i}}: {{pascal $s}}Service in {{kebab project_name}}
+func {{pascal $s}}() string { return {{quote (upper $s)}} }
+{{end}}
+`
+	for i := 0; i < files; i++ {
+		fsys[fmt.Sprintf("templates/pkg%d/file%d.go", i%50, i)] = &fstest.MapFile{Data: []byte(body), Mode: 0644}
+	}
+	fsys["templates/handlers/{{item}}_handler.go"] = &fstest.MapFile{Data: []byte(body), Mode: 0644}
+
+	return Source{
+		FS:       fsys,
+		Dir:      KitTemplatesDir,
+		Partials: PartialsDir,
+		Kit: &types.Kit{
+			Foreach: map[string]string{"handlers/{{item}}_handler.go": "resources"},
+		},
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	src := benchmarkSource(1000)
+	values := Placeholders{
+		"project_name": "bench-project",
+		"module":       "github.com/acme/bench",
+		"resources":    strings.Repeat("users,orders,", 50),
+	}
+
+	for _, jobs := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				e := New(values)
+				e.SetJobs(jobs)
+				if err := e.Generate(src, filepath.Join(b.TempDir(), "out")); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRegenerate(b *testing.B) {
+	src := benchmarkSource(1000)
+	values := Placeholders{
+		"project_name": "bench-project",
+		"module":       "github.com/acme/bench",
+		"resources":    strings.Repeat("users,orders,", 50),
+	}
+	out := filepath.Join(b.TempDir(), "out")
+
+	e := New(values)
+	e.SetIncremental(NewManifest(), false, false)
+	if err := e.Generate(src, out); err != nil {
+		b.Fatal(err)
+	}
+	manifest := e.Manifest()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := New(values)
+		e.SetIncremental(manifest, false, false)
+		if err := e.Generate(src, out); err != nil {
+			b.Fatal(err)
+		}
+		if written := len(e.IncrementalReport().Written); written != 0 {
+			b.Fatalf("regenerating unchanged source wrote %d files", written)
+		}
+	}
+}
+
+func TestIncrementalKeepsEditedFiles(t *testing.T) {
+	source := func(body string) Source {
+		return Source{
+			FS: fstest.MapFS{
+				"templates/a.txt": {Data: []byte(body), Mode: 0644},
+				"templates/b.txt": {Data: []byte(body), Mode: 0644},
+			},
+			Dir: KitTemplatesDir,
+		}
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	generate := func(body string, manifest *Manifest, force bool) *Engine {
+		t.Helper()
+		e := New(Placeholders{"name": "demo"})
+		e.SetIncremental(manifest, false, force)
+		if err := e.Generate(source(body), out); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Manifest().Refresh(out); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	manifest := generate("v1 {{name}}", NewManifest(), false).Manifest()
+	if err := os.WriteFile(filepath.Join(out, "a.txt"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed inputs rewrite b.txt but keep the edited a.txt, twice in a row
+	for i := 0; i < 2; i++ {
+		e := generate("v2 {{name}}", manifest, false)
+		manifest = e.Manifest()
+		report := e.IncrementalReport()
+		if got := strings.Join(report.Edited, ","); got != "a.txt" {
+			t.Errorf("run %d: edited = %q, want a.txt", i, got)
+		}
+		if got := read("a.txt"); got != "edited" {
+			t.Errorf("run %d: a.txt = %q, want the edit kept", i, got)
+		}
+		if got := read("b.txt"); got != "v2 demo" {
+			t.Errorf("run %d: b.txt = %q, want v2 demo", i, got)
+		}
+	}
+
+	e := generate("v2 {{name}}", manifest, true)
+	if edited := e.IncrementalReport().Edited; len(edited) != 0 {
+		t.Errorf("force: edited = %v, want none", edited)
+	}
+	if got := read("a.txt"); got != "v2 demo" {
+		t.Errorf("force: a.txt = %q, want v2 demo", got)
+	}
+}
+
+func TestKeepMarkersAreStripped(t *testing.T) {
+	src := Source{
+		FS: fstest.MapFS{
+			"templates/logs/.keep":     {Data: nil},
+			"templates/bin/.gitkeep":   {Data: nil},
+			"templates/docs/.keepme":   {Data: []byte("kept")},
+			"templates/docs/README.md": {Data: []byte("# {{name}}")},
+		},
+		Dir: KitTemplatesDir,
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	if err := New(Placeholders{"name": "demo"}).Generate(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{"logs", "bin"} {
+		entries, err := os.ReadDir(filepath.Join(out, dir))
+		if err != nil {
+			t.Errorf("%s: %v", dir, err)
+		} else if len(entries) != 0 {
+			t.Errorf("%s holds %v, want the marker stripped", dir, entries)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "docs", ".keepme")); err != nil {
+		t.Errorf("other dot files must be generated: %v", err)
+	}
+}
+
+// generatedFiles returns the sorted paths of the files below out
+func generatedFiles(t *testing.T, out string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(out, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(out, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// emptyDirs returns the directories below out that hold nothing
+func emptyDirs(t *testing.T, out string) []string {
+	t.Helper()
+	var dirs []string
+	err := filepath.WalkDir(out, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == out {
+			return err
+		}
+		entries, err := os.ReadDir(path)
+		if err == nil && len(entries) == 0 {
+			rel, _ := filepath.Rel(out, path)
+			dirs = append(dirs, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dirs
+}
+
+func TestConditions(t *testing.T) {
+	values := map[string]string{"database": "postgres", "cache": "", "docker": "yes", "debug": "off"}
+	lookup := func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`database == "postgres"`, true},
+		{`database != "postgres"`, false},
+		{`docker`, true},
+		{`debug`, false},
+		{`cache`, false},
+		{`undefined`, false},
+		{`undefined == ""`, true},
+		{`!cache && docker`, true},
+		{`cache || debug`, false},
+		{`not cache and (database == "mysql" or docker)`, true},
+		{`database == "postgres" && !(docker && debug)`, true},
+		{`true and not false`, true},
+	}
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := cond.Eval(lookup); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", `a ==`, `(a`, `a b`, `"unterminated`, `a & b`} {
+		if _, err := ParseCondition(expr); err == nil {
+			t.Errorf("ParseCondition(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestConditionalFiles(t *testing.T) {
+	src := Source{
+		FS: fstest.MapFS{
+			"templates/main.go":                    {Data: []byte("package main")},
+			"templates/Dockerfile":                 {Data: []byte("FROM scratch")},
+			"templates/cache/redis.go":             {Data: []byte("package cache")},
+			"templates/internal/cache/cache.go":    {Data: []byte("package cache")},
+			"templates/internal/cache/ttl/ttl.go":  {Data: []byte("package ttl")},
+			"templates/db/{{database}}/schema.sql": {Data: []byte("-- schema")},
+			"templates/{{deploy_dir}}/k8s.yaml":    {Data: []byte("kind: Deployment")},
+		},
+		Dir: KitTemplatesDir,
+		Kit: &types.Kit{Conditions: map[string]string{
+			"Dockerfile":        "docker",
+			"cache/**":          `cache != ""`,
+			"internal/cache/**": `cache != ""`,
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		values Placeholders
+		want   []string
+	}{
+		{"all", Placeholders{"database": "postgres", "cache": "redis", "docker": "true", "deploy_dir": "deploy"},
+			[]string{"Dockerfile", "cache/redis.go", "db/postgres/schema.sql", "deploy/k8s.yaml",
+				"internal/cache/cache.go", "internal/cache/ttl/ttl.go", "main.go"}},
+		// Paths with a segment that renders empty are skipped
+		{"none", Placeholders{"database": "", "cache": "", "docker": "false", "deploy_dir": ""},
+			[]string{"main.go"}},
+	}
+	for _, tt := range tests {
+		out := filepath.Join(t.TempDir(), "out")
+		if err := New(tt.values).Generate(src, out); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := generatedFiles(t, out); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: generated %v, want %v", tt.name, got, tt.want)
+		}
+		// Excluded subtrees leave no empty directories behind
+		if empty := emptyDirs(t, out); len(empty) > 0 {
+			t.Errorf("%s: empty directories %v", tt.name, empty)
+		}
+	}
+
+	src.Kit = &types.Kit{Conditions: map[string]string{"*": "a =="}}
+	if err := New(Placeholders{}).Generate(src, t.TempDir()); err == nil {
+		t.Error("Generate accepted an invalid condition")
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"users", []string{"users"}},
+		{" users, orders,, ", []string{"users", "orders"}},
+		{"[users, orders]", []string{"users", "orders"}},
+		{"- users\n- orders\n", []string{"users", "orders"}},
+		{"[unterminated, list", []string{"[unterminated", "list"}},
+	}
+	for _, tt := range tests {
+		if got := ParseList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseList(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestForeach(t *testing.T) {
+	src := Source{
+		FS: fstest.MapFS{
+			"templates/main.go":                           {Data: []byte("package main")},
+			"templates/handlers/{{item}}_handler.go":      {Data: []byte("// {{index}}: {{pascal item}} of {{project_name}}")},
+			"templates/migrations/{{index}}_{{item}}.sql": {Data: []byte("CREATE TABLE {{item}};")},
+		},
+		Dir: KitTemplatesDir,
+		Kit: &types.Kit{Foreach: map[string]string{
+			"handlers/*":   "resources",
+			"migrations/*": "tables",
+		}},
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	e := New(Placeholders{"project_name": "demo", "resources": "users, orders", "tables": ""})
+	if err := e.Generate(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"handlers/orders_handler.go", "handlers/users_handler.go", "main.go"}
+	if got := generatedFiles(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("generated %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "handlers", "orders_handler.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "// 1: Orders of demo"; got != want {
+		t.Errorf("orders_handler.go = %q, want %q", got, want)
+	}
+
+	if lists := ForeachLists(src.Kit); !reflect.DeepEqual(lists, []string{"resources", "tables"}) {
+		t.Errorf("ForeachLists = %v", lists)
+	}
+}
+
+// With [[ ]] delimiters foreach keys look like character classes; a key equal
+// to the template path must still select it, and an escaped glob must too
+func TestForeachBracketDelimiters(t *testing.T) {
+	src := Source{
+		FS: fstest.MapFS{
+			"templates/handlers/[[item]].go":   {Data: []byte("package [[item]]")},
+			"templates/tests/[[item]]_test.go": {Data: []byte("package [[item]]")},
+			"templates/handlers/i].go":         {Data: []byte("package i")},
+		},
+		Dir: KitTemplatesDir,
+		Kit: &types.Kit{
+			Delimiters: &types.Delimiters{Left: "[[", Right: "]]"},
+			Foreach: map[string]string{
+				"handlers/[[item]].go":  "resources",
+				`tests/\[\[item]]_*.go`: "resources",
+			},
+		},
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	if err := New(Placeholders{"resources": "orders, users"}).Generate(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"handlers/i].go",
+		"handlers/orders.go",
+		"handlers/users.go",
+		"tests/orders_test.go",
+		"tests/users_test.go",
+	}
+	if got := generatedFiles(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("generated %v, want %v", got, want)
+	}
+}
+
+func TestPartials(t *testing.T) {
+	partials := map[string]string{
+		"header.tmpl":          "// {{.project_name}} by {{.author}}",
+		"ci/steps.yaml":        "- run: go build\n- run: go test ./{{.project_name}}/...",
+		"deploy/notes.md.tmpl": "Deploy {{.project_name}}",
+	}
+	files := map[string]string{
+		"main.go":         `{{template "header" .}}` + "\npackage main\n",
+		".github/ci.yaml": "jobs:\n  build:\n    steps:{{include \"ci/steps\" . | nindent 6}}\n",
+		"docs/DEPLOY.md":  `{{template "deploy/notes.md" .}}`,
+	}
+	want := map[string]string{
+		"main.go":         "// demo by ana\npackage main\n",
+		".github/ci.yaml": "jobs:\n  build:\n    steps:\n      - run: go build\n      - run: go test ./demo/...\n",
+		"docs/DEPLOY.md":  "Deploy demo",
+	}
+
+	// Kits keep partials next to templates/, built-in templates keep them
+	// inside the template directory
+	layouts := []struct {
+		name     string
+		dir      string
+		partials string
+		skip     []string
+	}{
+		{"kit", KitTemplatesDir, PartialsDir, nil},
+		{"template", "api", "api/" + PartialsDir, []string{PartialsDir}},
+	}
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, content := range partials {
+				fsys[layout.partials+"/"+name] = &fstest.MapFile{Data: []byte(content)}
+			}
+			for name, content := range files {
+				fsys[layout.dir+"/"+name] = &fstest.MapFile{Data: []byte(content)}
+			}
+			src := Source{FS: fsys, Dir: layout.dir, Partials: layout.partials, Skip: layout.skip}
+			out := filepath.Join(t.TempDir(), "out")
+			if err := New(Placeholders{"project_name": "demo", "author": "ana"}).Generate(src, out); err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for name := range want {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if got := generatedFiles(t, out); !reflect.DeepEqual(got, names) {
+				t.Errorf("generated %v, want %v", got, names)
+			}
+			for name, content := range want {
+				data, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != content {
+					t.Errorf("%s = %q, want %q", name, data, content)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(out, PartialsDir)); !os.IsNotExist(err) {
+				t.Error("the partials directory was generated")
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// KitTemplatesDir holds the files a kit generates
+const KitTemplatesDir = "templates"
+
+// placeholderPattern matches a single action between the given delimiters
+func placeholderPattern(delims types.Delimiters) *regexp.Regexp {
+	return delimPattern("placeholder", delims, func(left, right string) string {
+		return left + `(.+?)` + right
+	})
+}
+
+// ExtractPlaceholders extracts all placeholders from the templates and
+// partials of a kit, honoring its delimiters and render/copy rules
+func ExtractPlaceholders(kit *types.Kit, kitFS fs.FS) ([]string, error) {
+	var placeholders []string
+	seen := make(map[string]bool)
+
+	classifier := NewFileClassifier(kit.Render, kit.Copy)
+
+	walkFn := func(root string, classify bool) fs.WalkDirFunc {
+		return func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || isSymlink(d) {
+				return nil
+			}
+
+			// Read file content
+			content, err := fs.ReadFile(kitFS, name)
+			if err != nil {
+				gl.Log("warn", fmt.Sprintf("Failed to read file %s: %v", name, err))
+				return nil // Continue walking
+			}
+
+			relPath := relPath(root, name)
+			if render, _ := classifier.Classify(relPath, content); classify && !render {
+				return nil
+			}
+			delims := DelimitersFor(kit, relPath)
+
+			// Extract placeholders from content, ignoring verbatim blocks
+			protected, _ := protectRawBlocks(string(content), delims)
+			matches := placeholderPattern(delims).FindAllStringSubmatch(protected, -1)
+
+			for _, match := range matches {
+				if len(match) > 1 {
+					placeholder := strings.TrimSpace(match[1])
+
+					// Clean up placeholder name (remove trim markers, dots, spaces, etc.)
+					placeholder = strings.TrimSpace(strings.TrimPrefix(placeholder, "-"))
+					placeholder = strings.TrimPrefix(placeholder, ".")
+					if idx := strings.Index(placeholder, " "); idx != -1 {
+						placeholder = placeholder[:idx]
+					}
+
+					if placeholder != "" && !seen[placeholder] {
+						placeholders = append(placeholders, placeholder)
+						seen[placeholder] = true
+					}
+				}
+			}
+
+			return nil
+		}
+	}
+
+	if err := fs.WalkDir(kitFS, KitTemplatesDir, walkFn(KitTemplatesDir, true)); err != nil {
+		return nil, fmt.Errorf("failed to walk templates directory: %w", err)
+	}
+
+	if _, err := fs.Stat(kitFS, PartialsDir); err == nil {
+		if err := fs.WalkDir(kitFS, PartialsDir, walkFn(PartialsDir, false)); err != nil {
+			return nil, fmt.Errorf("failed to walk partials directory: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to access partials directory: %w", err)
+	}
+
+	return placeholders, nil
+}
@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// keepFiles mark an otherwise empty template directory. The directory is
+// generated, the marker itself is not.
+var keepFiles = map[string]bool{".keep": true, ".gitkeep": true}
+
+// parseFileMode parses an octal permission string such as "0755" or "644"
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid file mode '%s'", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// ValidateModes checks the kit's mode overrides
+func ValidateModes(kit *types.Kit) error {
+	for _, glob := range sortedKeys(kit.Modes) {
+		if _, err := parseFileMode(kit.Modes[glob]); err != nil {
+			return fmt.Errorf("modes[%s]: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// kitModeFor returns the permissions for a generated file: the first
+// matching override in glob order, otherwise the source file's permissions
+func kitModeFor(kit *types.Kit, relPath string, sourceMode os.FileMode) os.FileMode {
+	for _, glob := range sortedKeys(kit.Modes) {
+		if MatchGlob(glob, relPath) {
+			if mode, err := parseFileMode(kit.Modes[glob]); err == nil {
+				return mode
+			}
+		}
+	}
+	return sourceMode.Perm()
+}
+
+// WriteFileMode writes content and applies mode even when the file already
+// existed or the umask would have narrowed it
+func WriteFileMode(targetPath string, content []byte, mode os.FileMode) error {
+	if err := os.WriteFile(targetPath, content, mode); err != nil {
+		return err
+	}
+	return os.Chmod(targetPath, mode)
+}
+
+// isSymlink reports whether a walked entry is a symbolic link
+func isSymlink(d fs.DirEntry) bool {
+	return d.Type()&fs.ModeSymlink != 0
+}
+
+// createSymlink recreates the symlink name from fsys as targetPath. The link
+// target is passed through render, must be relative and must resolve to a
+// path inside outputPath.
+func createSymlink(fsys fs.FS, name, targetPath, outputPath string, render func(string) string) error {
+	linkTarget, err := ReadLink(fsys, name)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink: %w", err)
+	}
+	linkTarget = render(linkTarget)
+
+	if filepath.IsAbs(linkTarget) {
+		return fmt.Errorf("symlink %s points to absolute path %s", targetPath, linkTarget)
+	}
+
+	resolved := filepath.Join(filepath.Dir(targetPath), linkTarget)
+	rel, err := filepath.Rel(outputPath, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink %s points outside the project: %s", targetPath, linkTarget)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	// Regenerating into an existing project replaces outdated links
+	if existing, err := os.Readlink(targetPath); err == nil {
+		if existing == linkTarget {
+			return nil
+		}
+		if err := os.Remove(targetPath); err != nil {
+			return fmt.Errorf("failed to replace symlink: %w", err)
+		}
+	}
+	return os.Symlink(linkTarget, targetPath)
+}
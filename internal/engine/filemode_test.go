@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// diskTemplates writes a templates directory below a temp dir: files with
+// their permissions and symlinks with their targets
+func diskTemplates(t *testing.T, files map[string]os.FileMode, links map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, mode := range files {
+		path := filepath.Join(dir, KitTemplatesDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFileMode(path, []byte(name+" of {{project_name}}\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, target := range links {
+		path := filepath.Join(dir, KitTemplatesDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFileModes(t *testing.T) {
+	dir := diskTemplates(t, map[string]os.FileMode{
+		"scripts/build.sh": 0755,
+		"scripts/env.sh":   0644,
+		"bin/tool":         0644,
+		"secrets/key.pem":  0644,
+		"README.md":        0444,
+	}, nil)
+	kit := &types.Kit{Modes: map[string]string{
+		"bin/*":        "0750",
+		"secrets/**":   "600",
+		"scripts/*.sh": "0755",
+	}}
+	if err := ValidateModes(kit); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	src := Source{FS: DirFS(dir), Dir: KitTemplatesDir, Kit: kit}
+	if err := New(Placeholders{"project_name": "demo"}).Generate(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		mode os.FileMode
+	}{
+		{"scripts/build.sh", 0755},
+		{"scripts/env.sh", 0755},
+		{"bin/tool", 0750},
+		{"secrets/key.pem", 0600},
+		// Read-only sources stay editable
+		{"README.md", 0644},
+	}
+	for _, tt := range tests {
+		info, err := os.Stat(filepath.Join(out, filepath.FromSlash(tt.name)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if info.Mode().Perm() != tt.mode {
+			t.Errorf("%s: mode %o, want %o", tt.name, info.Mode().Perm(), tt.mode)
+		}
+	}
+
+	for _, mode := range []string{"0888", "rwx", "01777"} {
+		if err := ValidateModes(&types.Kit{Modes: map[string]string{"*": mode}}); err == nil {
+			t.Errorf("ValidateModes accepted %q", mode)
+		}
+	}
+}
+
+func TestSymlinks(t *testing.T) {
+	dir := diskTemplates(t, map[string]os.FileMode{
+		"CHANGELOG.md":            0644,
+		"releases/{{version}}.md": 0644,
+	}, map[string]string{
+		"docs/CHANGELOG.md": "../CHANGELOG.md",
+		"LATEST.md":         "releases/{{version}}.md",
+	})
+
+	target, err := ReadLink(DirFS(dir), KitTemplatesDir+"/docs/CHANGELOG.md")
+	if err != nil || target != "../CHANGELOG.md" {
+		t.Errorf("ReadLink = %q, %v", target, err)
+	}
+	if _, err := ReadLink(DirFS(dir), "../"+KitTemplatesDir); err == nil {
+		t.Error("ReadLink accepted a path outside the filesystem")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	src := Source{FS: DirFS(dir), Dir: KitTemplatesDir}
+	if err := New(Placeholders{"project_name": "demo", "version": "v1"}).Generate(src, out); err != nil {
+		t.Fatal(err)
+	}
+
+	links := map[string]string{
+		"docs/CHANGELOG.md": "../CHANGELOG.md",
+		"LATEST.md":         "releases/v1.md",
+	}
+	for name, want := range links {
+		path := filepath.Join(out, filepath.FromSlash(name))
+		if got, err := os.Readlink(path); err != nil || got != want {
+			t.Errorf("%s links to %q (%v), want %q", name, got, err, want)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s is dangling: %v", name, err)
+		}
+	}
+}
+
+func TestSymlinksEscapingOutput(t *testing.T) {
+	tests := []struct {
+		name, target string
+	}{
+		{"absolute", "/etc/passwd"},
+		{"parent", "../../outside"},
+		{"rendered parent", "{{up}}/outside"},
+		{"through a subdirectory", "sub/../../../outside"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := diskTemplates(t, nil, map[string]string{"docs/link": tt.target})
+			out := filepath.Join(t.TempDir(), "out")
+			src := Source{FS: DirFS(dir), Dir: KitTemplatesDir}
+			if err := New(Placeholders{"up": "../.."}).Generate(src, out); err == nil {
+				t.Error("Generate succeeded, want an error")
+			}
+			if _, err := os.Lstat(filepath.Join(out, "docs", "link")); err == nil {
+				t.Error("escaping symlink was created")
+			}
+		})
+	}
+}
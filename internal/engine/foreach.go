@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Placeholders set for each element while a foreach rule expands a file
+const (
+	LoopItemPlaceholder  = "item"
+	LoopIndexPlaceholder = "index"
+)
+
+// ParseList splits a placeholder value into list elements. Values may be a
+// YAML list ("[orders, users]" or "- orders\n- users") or comma-separated
+// ("orders, users"). Empty elements are dropped.
+func ParseList(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "- ") {
+		var items []string
+		if err := yaml.Unmarshal([]byte(value), &items); err == nil {
+			return compactList(items)
+		}
+	}
+
+	return compactList(strings.Split(value, ","))
+}
+
+func compactList(items []string) []string {
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// kitLoopFor returns the list placeholder that expands the template file at
+// relPath, if any foreach rule matches. A key equal to relPath wins; other
+// rules are checked in glob order.
+func kitLoopFor(kit *types.Kit, relPath string) (string, bool) {
+	if list, ok := kit.Foreach[filepath.ToSlash(relPath)]; ok {
+		return list, true
+	}
+	for _, glob := range sortedKeys(kit.Foreach) {
+		if MatchGlob(glob, relPath) {
+			return kit.Foreach[glob], true
+		}
+	}
+	return "", false
+}
+
+// ForeachLists returns the list placeholders named by the kit's foreach rules
+func ForeachLists(kit *types.Kit) []string {
+	var lists []string
+	for _, glob := range sortedKeys(kit.Foreach) {
+		lists = append(lists, kit.Foreach[glob])
+	}
+	return lists
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
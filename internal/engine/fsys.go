@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ReadLinkFS is implemented by filesystems that can report symlink targets.
+// Templates read from other filesystems can't contain symlinks.
+type ReadLinkFS interface {
+	fs.FS
+	ReadLink(name string) (string, error)
+}
+
+// DirFS returns the filesystem rooted at dir. Unlike os.DirFS it implements
+// ReadLinkFS, so symlinks in kits and template directories are preserved.
+func DirFS(dir string) fs.FS {
+	return dirFS{FS: os.DirFS(dir), dir: dir}
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func (d dirFS) ReadLink(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.Readlink(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
+
+// ReadLink returns the target of the symlink name in fsys
+func ReadLink(fsys fs.FS, name string) (string, error) {
+	rl, ok := fsys.(ReadLinkFS)
+	if !ok {
+		return "", fmt.Errorf("symlink %s: filesystem doesn't support symlinks", name)
+	}
+	return rl.ReadLink(name)
+}
+
+// relPath returns name relative to root, both slash-separated fs.FS paths
+func relPath(root, name string) string {
+	if root == "." {
+		return name
+	}
+	if name == root {
+		return "."
+	}
+	return name[len(root)+1:]
+}
+
+// sourceFileMode returns the permissions generated files inherit from a
+// template file. Embedded files report 0444, so the owner write bit is always
+// added to keep generated projects editable.
+func sourceFileMode(d fs.DirEntry) (os.FileMode, error) {
+	info, err := d.Info()
+	if err != nil {
+		return 0, err
+	}
+	return info.Mode().Perm() | 0200, nil
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// MatchGlob reports whether the slash-separated relative path name matches
+// pattern. Patterns use path.Match syntax per segment, plus "**" matching
+// any number of segments (including none). A pattern without a slash is
+// matched against the base name only, like .gitignore entries. A pattern
+// equal to name always matches, so literal paths holding "[" or "*" (such as
+// "[[item]].go" in kits with [[ ]] delimiters) need no escaping.
+func MatchGlob(pattern, name string) bool {
+	pattern = filepath.ToSlash(strings.TrimPrefix(pattern, "./"))
+	name = filepath.ToSlash(name)
+	if pattern == name {
+		return true
+	}
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// MatchAnyGlob reports whether name matches at least one of the patterns
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,359 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// manifestVersion changes whenever input hashes change meaning; manifests of
+// other versions are ignored, so the next run regenerates everything
+const manifestVersion = 1
+
+// linkHashPrefix marks manifest outputs that are symlinks rather than files
+const linkHashPrefix = "link:"
+
+// Manifest records a generation into a directory: for every generated file,
+// keyed by its slash-separated path relative to the project, the hash of
+// everything it was rendered from and the hash of its contents. An
+// incremental generation uses the previous manifest to skip files whose
+// inputs and contents are unchanged.
+type Manifest struct {
+	Version int                      `json:"version"`
+	Answers map[string]string        `json:"answers,omitempty"` // Values the project was generated with, reused by later runs
+	Files   map[string]ManifestEntry `json:"files"`
+	Stale   map[string]ManifestEntry `json:"stale,omitempty"` // Files no longer produced but kept, as last generated
+
+	edited map[string]bool // Files kept because they were edited; Refresh leaves their hash
+}
+
+// ManifestEntry is the recorded state of one generated file
+type ManifestEntry struct {
+	Input  string `json:"input"`  // Hash of the source file, its path, mode, loop element and all shared template data
+	Output string `json:"output"` // Hash of the contents on disk, or "link:" and the target of a symlink
+}
+
+// NewManifest creates an empty manifest
+func NewManifest() *Manifest {
+	return &Manifest{
+		Version: manifestVersion,
+		Files:   make(map[string]ManifestEntry),
+		Stale:   make(map[string]ManifestEntry),
+	}
+}
+
+// LoadManifest reads a manifest. A missing manifest, or one written by
+// another version, yields an empty one.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := NewManifest()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.Version != manifestVersion {
+		gl.Log("debug", fmt.Sprintf("Ignoring manifest %s of version %d", path, m.Version))
+		return NewManifest(), nil
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]ManifestEntry)
+	}
+	if m.Stale == nil {
+		m.Stale = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// Save writes the manifest to path, creating its directory
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Refresh re-hashes the recorded files below outputPath, so changes made
+// after rendering (formatters, license headers) are not mistaken for local
+// edits by the next run. Files that no longer exist are dropped; stale and
+// edited files keep the hash they were generated with.
+func (m *Manifest) Refresh(outputPath string) error {
+	for name, entry := range m.Files {
+		if m.edited[name] {
+			continue
+		}
+		output, err := hashOutput(filepath.Join(outputPath, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			delete(m.Files, name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		entry.Output = output
+		m.Files[name] = entry
+	}
+	return nil
+}
+
+// IncrementalReport summarizes an incremental generation; paths are relative
+// to the project
+type IncrementalReport struct {
+	Written   []string // Files rendered and written
+	Unchanged []string // Files whose inputs or contents were unchanged
+	Edited    []string // Files edited since they were generated, kept as-is
+	Stale     []string // Files of the previous generation no longer produced
+	Removed   []string // Stale files deleted because pruning was requested
+}
+
+// incremental is the state of an incremental generation
+type incremental struct {
+	prev  *Manifest
+	next  *Manifest
+	prune bool
+	force bool   // Overwrite files edited since they were generated
+	env   string // Hash of the data, functions, partials and rules shared by every file
+
+	mu     sync.Mutex
+	report IncrementalReport
+}
+
+// SetIncremental makes Generate regenerate into a directory previously
+// generated with manifest prev: files whose inputs and contents are unchanged
+// are neither rendered nor written, and files prev lists that are no longer
+// produced are reported, or deleted when prune is set and they were not
+// edited since. Files edited since they were generated are kept and
+// reported, unless force is set. A nil prev disables incremental generation.
+func (e *Engine) SetIncremental(prev *Manifest, prune, force bool) {
+	if prev == nil {
+		e.inc = nil
+		return
+	}
+	e.inc = &incremental{prev: prev, prune: prune, force: force}
+}
+
+// Manifest returns the manifest of the last incremental Generate, or nil
+func (e *Engine) Manifest() *Manifest {
+	if e.inc == nil {
+		return nil
+	}
+	return e.inc.next
+}
+
+// IncrementalReport returns what the last incremental Generate did, or nil
+func (e *Engine) IncrementalReport() *IncrementalReport {
+	if e.inc == nil || e.inc.next == nil {
+		return nil
+	}
+	report := e.inc.report
+	for _, list := range [][]string{report.Written, report.Unchanged, report.Edited, report.Stale, report.Removed} {
+		sort.Strings(list)
+	}
+	return &report
+}
+
+// start prepares a generation of src, hashing everything files share
+func (inc *incremental) start(e *Engine, src Source, kit *types.Kit) {
+	h := sha256.New()
+	fmt.Fprintf(h, "gocrafter manifest %d\n", manifestVersion)
+
+	names := make([]string, 0, len(e.data))
+	for name := range e.data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "value %q=%#v\n", name, e.data[name])
+	}
+	for _, name := range e.partials.Names() {
+		writeField(h, "partial "+name, e.partials[name])
+	}
+	rules, err := json.Marshal(kit)
+	if err != nil {
+		rules = []byte(err.Error())
+	}
+	writeField(h, "kit", string(rules))
+	writeField(h, "fingerprint", src.Fingerprint)
+
+	inc.env = hex.EncodeToString(h.Sum(nil))
+	inc.next = NewManifest()
+	inc.next.edited = make(map[string]bool)
+	inc.next.Answers = inc.prev.Answers
+	inc.report = IncrementalReport{}
+}
+
+// inputHash identifies everything a file's output depends on
+func (inc *incremental) inputHash(relPath string, content []byte, mode os.FileMode, data map[string]any) string {
+	h := sha256.New()
+	writeField(h, "env", inc.env)
+	writeField(h, "path", relPath)
+	writeField(h, "mode", strconv.FormatUint(uint64(mode), 8))
+	if item, ok := data[LoopItemPlaceholder]; ok {
+		writeField(h, "item", formatValue(item))
+		writeField(h, "index", formatValue(data[LoopIndexPlaceholder]))
+	}
+	writeField(h, "content", string(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// keep reports whether the file at name is left as-is: it still holds what
+// the previous generation wrote from the same input, or it was edited since it
+// was generated and force is not set. Edited files keep their previous entry,
+// so later runs protect them too.
+func (inc *incremental) keep(name, input, targetPath string) bool {
+	entry, ok := inc.prev.Files[name]
+	if !ok {
+		return false
+	}
+	output, err := hashOutput(targetPath)
+	if err != nil {
+		return false
+	}
+	if output != entry.Output {
+		if inc.force {
+			return false
+		}
+		inc.mu.Lock()
+		defer inc.mu.Unlock()
+		inc.next.Files[name] = entry
+		inc.next.edited[name] = true
+		inc.report.Edited = append(inc.report.Edited, name)
+		return true
+	}
+	if entry.Input != input {
+		return false
+	}
+	inc.record(name, entry, false)
+	return true
+}
+
+// record adds a produced file to the new manifest
+func (inc *incremental) record(name string, entry ManifestEntry, written bool) {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	inc.next.Files[name] = entry
+	if written {
+		inc.report.Written = append(inc.report.Written, name)
+	} else {
+		inc.report.Unchanged = append(inc.report.Unchanged, name)
+	}
+}
+
+// recordLink adds a symlink created by the walk phase to the new manifest
+func (inc *incremental) recordLink(name, targetPath string) error {
+	output, err := hashOutput(targetPath)
+	if err != nil {
+		return err
+	}
+	entry := ManifestEntry{Input: output, Output: output}
+	inc.record(name, entry, inc.prev.Files[name] != entry)
+	return nil
+}
+
+// finish reports, and with pruning deletes, the files of previous
+// generations that were not produced this time. Stale files edited since they
+// were generated are never deleted; kept ones are remembered, so they are
+// reported again and can be pruned later.
+func (inc *incremental) finish(outputPath string) error {
+	previous := make(map[string]ManifestEntry, len(inc.prev.Files)+len(inc.prev.Stale))
+	for name, entry := range inc.prev.Stale {
+		previous[name] = entry
+	}
+	for name, entry := range inc.prev.Files {
+		previous[name] = entry
+	}
+
+	for name, entry := range previous {
+		if _, ok := inc.next.Files[name]; ok {
+			continue
+		}
+		targetPath := filepath.Join(outputPath, filepath.FromSlash(name))
+		output, err := hashOutput(targetPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		inc.report.Stale = append(inc.report.Stale, name)
+		if !inc.prune || output != entry.Output {
+			if inc.prune {
+				gl.Log("warn", fmt.Sprintf("Keeping %s: modified since it was generated", name))
+			}
+			inc.next.Stale[name] = entry
+			continue
+		}
+		if err := os.Remove(targetPath); err != nil {
+			return fmt.Errorf("failed to remove stale file: %w", err)
+		}
+		inc.report.Removed = append(inc.report.Removed, name)
+		removeEmptyParents(filepath.Dir(targetPath), outputPath)
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents up to root while they are empty
+func removeEmptyParents(dir, root string) {
+	for dir != root && len(dir) > len(root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// hashOutput hashes a generated file, or the target of a generated symlink
+func hashOutput(path string) (string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return linkHashPrefix + target, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(content), nil
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeField writes a length-prefixed field, so adjacent fields can't collide
+func writeField(h hash.Hash, name, value string) {
+	fmt.Fprintf(h, "%s %d\n%s\n", name, len(value), value)
+}
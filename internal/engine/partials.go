@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// PartialsDir is the directory holding shared named templates, both at the
+// root of a kit and inside a built-in template. Its files are never emitted.
+const PartialsDir = "partials"
+
+// Partials maps partial names to their template source.
+// A partial's name is its path relative to the partials directory without
+// extension, e.g. partials/ci/github.yaml.tmpl is "ci/github.yaml".
+type Partials map[string]string
+
+// LoadPartials reads every file below dir in fsys as a partial. A missing
+// directory yields an empty set.
+func LoadPartials(fsys fs.FS, dir string) (Partials, error) {
+	partials := make(Partials)
+
+	info, err := fs.Stat(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return partials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to access partials directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("partials path is not a directory: %s", dir)
+	}
+
+	err = fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel := relPath(dir, name)
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read partial %s: %w", rel, err)
+		}
+
+		partials[strings.TrimSuffix(rel, path.Ext(rel))] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return partials, nil
+}
+
+// Names returns the sorted partial names
+func (p Partials) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has reports whether a partial with the given name exists
+func (p Partials) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// includePlaceholder lets templates that call include be parsed before the
+// real implementation, which needs the parsed template set, is bound
+func includePlaceholder(string, interface{}) (string, error) {
+	return "", fmt.Errorf("include called before template was bound")
+}
+
+// attachPartials parses the partials into tmpl as named templates
+func attachPartials(tmpl *template.Template, partials Partials) error {
+	for _, name := range partials.Names() {
+		if _, err := tmpl.New(name).Parse(partials[name]); err != nil {
+			return fmt.Errorf("failed to parse partial '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// includeFunc executes a template of tmpl's set so partial output can be
+// piped (e.g. into nindent)
+func includeFunc(tmpl *template.Template) func(string, interface{}) (string, error) {
+	return func(name string, data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
@@ -0,0 +1,246 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"text/template"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// baseKey identifies a base template: the functions and partials every file
+// is parsed on top of. Files rendered for a foreach element also know item
+// and index, so they get their own base.
+type baseKey struct {
+	delims types.Delimiters
+	loop   bool
+}
+
+// parseKey identifies a parsed file or path segment
+type parseKey struct {
+	name    string
+	content string
+	base    baseKey
+}
+
+// parsed is a template parsed once and cloned for every execution
+type parsed struct {
+	tmpl      *template.Template
+	protected string // Source with verbatim blocks replaced by sentinels
+	rawBlocks []string
+	err       error
+}
+
+// resetCache drops parsed templates, which depend on the engine's values,
+// functions and partials
+func (e *Engine) resetCache() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bases = nil
+	e.cache = nil
+}
+
+func (e *Engine) render(name, content string, delims types.Delimiters, data map[string]any) (string, error) {
+	delims = normalizeDelimiters(delims)
+	key := parseKey{name: name, content: content, base: baseKey{delims: delims, loop: e.isLoopData(data)}}
+	p := e.parse(key, data)
+	if p.err != nil {
+		return e.fallback(p, delims, data, p.err)
+	}
+
+	// Every execution gets its own clone, bound to its data
+	tmpl, err := p.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(template.FuncMap{
+		dataFunc:  func() map[string]any { return data },
+		"include": includeFunc(tmpl),
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return e.fallback(p, delims, data, err)
+	}
+
+	return restoreRawBlocks(buf.String(), p.rawBlocks), nil
+}
+
+// isLoopData reports whether data holds a foreach element on top of the
+// engine's values
+func (e *Engine) isLoopData(data map[string]any) bool {
+	_, inData := data[LoopItemPlaceholder]
+	_, inEngine := e.data[LoopItemPlaceholder]
+	return inData && !inEngine
+}
+
+// parse returns the cached parse of key, parsing it on first use. Parsing
+// happens outside the lock so workers parse different files concurrently.
+func (e *Engine) parse(key parseKey, data map[string]any) *parsed {
+	e.mu.Lock()
+	p, ok := e.cache[key]
+	e.mu.Unlock()
+	if ok {
+		return p
+	}
+
+	p = e.parseFile(key, data)
+
+	e.mu.Lock()
+	if e.cache == nil {
+		e.cache = make(map[parseKey]*parsed)
+	}
+	e.cache[key] = p
+	e.mu.Unlock()
+	return p
+}
+
+func (e *Engine) parseFile(key parseKey, data map[string]any) *parsed {
+	// Protect verbatim blocks from template processing
+	protected, rawBlocks := protectRawBlocks(key.content, key.base.delims)
+	p := &parsed{protected: protected, rawBlocks: rawBlocks}
+
+	base, err := e.base(key.base, data)
+	if err != nil {
+		p.err = err
+		return p
+	}
+	tmpl, err := base.Clone()
+	if err != nil {
+		p.err = err
+		return p
+	}
+	if tmpl, err = tmpl.New(key.name).Parse(protected); err != nil {
+		p.err = err
+		return p
+	}
+
+	// Resolving is idempotent, so partials shared with the base are left as they are
+	resolver := shorthand{data: data, funcs: e.funcs}
+	for _, t := range tmpl.Templates() {
+		resolver.resolve(t.Tree)
+	}
+	p.tmpl = tmpl
+	return p
+}
+
+// base returns the functions and partials for key, parsing them on first use
+func (e *Engine) base(key baseKey, data map[string]any) (*template.Template, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if b, ok := e.bases[key]; ok {
+		return b.tmpl, b.err
+	}
+
+	tmpl := template.New("").Delims(key.delims.Left, key.delims.Right).Funcs(shorthandFuncs(data)).Funcs(e.funcs)
+	b := &parsed{tmpl: tmpl}
+	if b.err = attachPartials(tmpl, e.partials); b.err == nil {
+		resolver := shorthand{data: data, funcs: e.funcs}
+		for _, t := range tmpl.Templates() {
+			resolver.resolve(t.Tree)
+		}
+	}
+
+	if e.bases == nil {
+		e.bases = make(map[baseKey]*parsed)
+	}
+	e.bases[key] = b
+	return b.tmpl, b.err
+}
+
+// fallback is used when content fails to parse or execute. Lenient engines
+// substitute the exact {{name}} and {{.name}} forms in a single pass and keep
+// everything else verbatim; strict engines return err.
+func (e *Engine) fallback(p *parsed, delims types.Delimiters, data map[string]any, err error) (string, error) {
+	if !e.lenient {
+		return "", e.templateError(err)
+	}
+	gl.Log("warn", fmt.Sprintf("Template processing failed, using simple replacement: %v", err))
+
+	pattern := shorthandPattern(delims)
+	substituted := pattern.ReplaceAllStringFunc(p.protected, func(action string) string {
+		name := pattern.FindStringSubmatch(action)[1]
+		if value, ok := lookupValue(data, name); ok {
+			return value
+		}
+		return action
+	})
+	return restoreRawBlocks(substituted, p.rawBlocks), nil
+}
+
+// TemplateError is a template that failed to parse or execute. Its message is
+// the one from text/template; the location is also available on its own.
+type TemplateError struct {
+	Name    string // Template name: a path relative to the source directory, or a partial name
+	Partial bool   // Whether Name is a partial
+	Line    int
+	Column  int    // 0 when unknown, as for parse errors
+	Message string // The problem, without the location
+	err     error
+}
+
+func (e *TemplateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.err
+}
+
+// templateErrorPattern matches "template: name:line[:column]: message"
+var templateErrorPattern = regexp.MustCompile(`(?s)^template: (.+?):(\d+):(?:(\d+):)? (.*)$`)
+
+// templateError locates a text/template error, possibly wrapped as for
+// partials; other errors are returned as is
+func (e *Engine) templateError(err error) error {
+	var match []string
+	for inner := err; inner != nil && match == nil; inner = errors.Unwrap(inner) {
+		match = templateErrorPattern.FindStringSubmatch(inner.Error())
+	}
+	if match == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(match[2])
+	column, _ := strconv.Atoi(match[3])
+	return &TemplateError{
+		Name:    match[1],
+		Partial: e.partials.Has(match[1]),
+		Line:    line,
+		Column:  column,
+		Message: match[4],
+		err:     err,
+	}
+}
+
+// patternKey identifies a compiled delimiter-dependent expression
+type patternKey struct {
+	kind   string
+	delims types.Delimiters
+}
+
+// patterns caches compiled expressions, which are needed for every file
+var patterns sync.Map
+
+// delimPattern returns the expression of kind for delims, compiling it with
+// build on first use
+func delimPattern(kind string, delims types.Delimiters, build func(left, right string) string) *regexp.Regexp {
+	key := patternKey{kind: kind, delims: delims}
+	if re, ok := patterns.Load(key); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(build(regexp.QuoteMeta(delims.Left), regexp.QuoteMeta(delims.Right)))
+	patterns.Store(key, re)
+	return re
+}
+
+// shorthandPattern matches {{name}} and {{.name}} with the given delimiters
+func shorthandPattern(delims types.Delimiters) *regexp.Regexp {
+	return delimPattern("shorthand", delims, func(left, right string) string {
+		return left + `\.?([A-Za-z_][A-Za-z0-9_-]*)` + right
+	})
+}
@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"text/template"
+	"text/template/parse"
+	"unicode"
+)
+
+// dataFunc returns the engine's top-level values; shorthand lookups are
+// rewritten into field lookups on its result
+const dataFunc = "_data"
+
+// builtinFuncs are the text/template built-in functions, which values never shadow
+var builtinFuncs = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true,
+	"len": true, "not": true, "or": true, "print": true, "printf": true, "println": true,
+	"urlquery": true, "eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// shorthandFuncs lets the parser accept the names of values in data as
+// identifiers, and binds dataFunc for parsing
+func shorthandFuncs(data map[string]any) template.FuncMap {
+	fm := template.FuncMap{
+		dataFunc: func() map[string]any { return data },
+	}
+	for name := range data {
+		if !isIdentifier(name) || builtinFuncs[name] {
+			continue
+		}
+		fm[name] = func() any { return data[name] }
+	}
+	return fm
+}
+
+// shorthand rewrites the {{name}} shorthand into the field lookup
+// {{(_data).name}}, so values are looked up like any other field and never
+// parsed as template source. An identifier is a value when it is used on its
+// own, as in {{name}} or {{name | upper}}, or when it names a value and no
+// function.
+type shorthand struct {
+	data  map[string]any
+	funcs template.FuncMap
+}
+
+// resolve rewrites the shorthand in a parsed tree
+func (s shorthand) resolve(tree *parse.Tree) {
+	if tree != nil && tree.Root != nil {
+		s.resolveNode(tree, tree.Root)
+	}
+}
+
+func (s shorthand) resolveNode(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			s.resolveNode(tree, child)
+		}
+	case *parse.ActionNode:
+		s.resolveNode(tree, n.Pipe)
+	case *parse.IfNode:
+		s.resolveBranch(tree, &n.BranchNode)
+	case *parse.RangeNode:
+		s.resolveBranch(tree, &n.BranchNode)
+	case *parse.WithNode:
+		s.resolveBranch(tree, &n.BranchNode)
+	case *parse.TemplateNode:
+		s.resolveNode(tree, n.Pipe)
+	case *parse.ChainNode:
+		s.resolveNode(tree, n.Node)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			s.resolveCommand(tree, cmd, i == 0)
+		}
+	}
+}
+
+func (s shorthand) resolveBranch(tree *parse.Tree, n *parse.BranchNode) {
+	s.resolveNode(tree, n.Pipe)
+	s.resolveNode(tree, n.List)
+	s.resolveNode(tree, n.ElseList)
+}
+
+// resolveCommand rewrites the value identifiers of cmd; first reports whether
+// cmd starts its pipeline, as later commands receive the previous result
+func (s shorthand) resolveCommand(tree *parse.Tree, cmd *parse.CommandNode, first bool) {
+	for i, arg := range cmd.Args {
+		ident, ok := arg.(*parse.IdentifierNode)
+		if !ok {
+			s.resolveNode(tree, arg)
+			continue
+		}
+		if _, isValue := s.data[ident.Ident]; !isValue {
+			continue
+		}
+		alone := first && i == 0 && len(cmd.Args) == 1
+		if !alone && (s.funcs[ident.Ident] != nil || builtinFuncs[ident.Ident]) {
+			continue
+		}
+		cmd.Args[i] = &parse.ChainNode{
+			NodeType: parse.NodeChain,
+			Pos:      ident.Pos,
+			Node:     parse.NewIdentifier(dataFunc).SetTree(tree).SetPos(ident.Pos),
+			Field:    []string{ident.Ident},
+		}
+	}
+}
+
+// isIdentifier reports whether name can be used as a template function name
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+package funcs
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func list(items ...interface{}) []interface{} {
+	return items
+}
+
+// split splits s on sep, trimming whitespace and dropping empty items,
+// so `{{ .resources | split "," }}` works on comma-separated answers
+func split(sep, s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// join joins a list with a separator (`join ", " .items`). Called with
+// anything but a separator and a list, such as only strings, it keeps its
+// original meaning and joins path elements.
+func join(args ...interface{}) (string, error) {
+	if len(args) == 2 && args[1] != nil {
+		if sep, ok := args[0].(string); ok {
+			if items, ok := toList(args[1]); ok {
+				parts := make([]string, len(items))
+				for i, item := range items {
+					parts[i] = toString(item)
+				}
+				return strings.Join(parts, sep), nil
+			}
+		}
+	}
+
+	elems := make([]string, len(args))
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			return "", fmt.Errorf("join: unsupported argument type %T", arg)
+		}
+		elems[i] = s
+	}
+	return filepath.Join(elems...), nil
+}
+
+func first(v interface{}) (interface{}, error) {
+	items, err := mustList("first", v)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+func last(v interface{}) (interface{}, error) {
+	items, err := mustList("last", v)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[len(items)-1], nil
+}
+
+func rest(v interface{}) ([]interface{}, error) {
+	items, err := mustList("rest", v)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return items[1:], nil
+}
+
+func uniq(v interface{}) ([]interface{}, error) {
+	items, err := mustList("uniq", v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []interface{}
+	for _, item := range items {
+		if !containsValue(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func has(needle interface{}, haystack interface{}) (bool, error) {
+	items, err := mustList("has", haystack)
+	if err != nil {
+		return false, err
+	}
+	return containsValue(items, needle), nil
+}
+
+func sortAlpha(v interface{}) ([]string, error) {
+	items, err := mustList("sortAlpha", v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = toString(item)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func appendList(v interface{}, item interface{}) ([]interface{}, error) {
+	items, err := mustList("append", v)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]interface{}{}, items...), item), nil
+}
+
+func dict(pairs ...interface{}) (map[string]interface{}, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: expected an even number of arguments, got %d", len(pairs))
+	}
+
+	result := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		result[toString(pairs[i])] = pairs[i+1]
+	}
+	return result, nil
+}
+
+func keys(v interface{}) ([]string, error) {
+	m, err := mustMap("keys", v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func values(v interface{}) ([]interface{}, error) {
+	names, err := keys(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m, _ := toMap(v)
+	result := make([]interface{}, len(names))
+	for i, name := range names {
+		result[i] = m[name]
+	}
+	return result, nil
+}
+
+func get(v interface{}, key string) (interface{}, error) {
+	m, err := mustMap("get", v)
+	if err != nil {
+		return nil, err
+	}
+	if value, ok := m[key]; ok {
+		return value, nil
+	}
+	return "", nil
+}
+
+func set(v interface{}, key string, value interface{}) (map[string]interface{}, error) {
+	m, err := mustMap("set", v)
+	if err != nil {
+		return nil, err
+	}
+	m[key] = value
+	return m, nil
+}
+
+func hasKey(v interface{}, key string) (bool, error) {
+	m, err := mustMap("hasKey", v)
+	if err != nil {
+		return false, err
+	}
+	_, ok := m[key]
+	return ok, nil
+}
+
+// merge returns a new map with the entries of every map, earlier maps winning
+func merge(maps ...interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, v := range maps {
+		m, err := mustMap("merge", v)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range m {
+			if _, exists := result[key]; !exists {
+				result[key] = value
+			}
+		}
+	}
+	return result, nil
+}
+
+func containsValue(items []interface{}, needle interface{}) bool {
+	for _, item := range items {
+		if reflect.DeepEqual(item, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustList(name string, v interface{}) ([]interface{}, error) {
+	items, ok := toList(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a list, got %T", name, v)
+	}
+	return items, nil
+}
+
+func toList(v interface{}) ([]interface{}, bool) {
+	if v == nil {
+		return nil, true
+	}
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items := make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+	return items, true
+}
+
+// mustMap returns v as a map. Maps of type map[string]interface{} are returned
+// as-is so `set` mutates them in place; other string-keyed maps are copied.
+func mustMap(name string, v interface{}) (map[string]interface{}, error) {
+	m, ok := toMap(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a map, got %T", name, v)
+	}
+	return m, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+
+	m := make(map[string]interface{}, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
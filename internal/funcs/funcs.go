@@ -0,0 +1,182 @@
+// Package funcs provides the template function library shared by the
+// built-in templates and kits rendered by the engine.
+package funcs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateEnvVar lists, comma-separated, environment variables templates may
+// read with env besides the safeEnv ones. Templates come from third-party
+// kits, so any other variable, which may hold a secret, must be opted into.
+const TemplateEnvVar = "GOCRAFTER_TEMPLATE_ENV"
+
+// safeEnv are the environment variables every template may read
+var safeEnv = map[string]bool{
+	"HOME":    true,
+	"USER":    true,
+	"LOGNAME": true,
+	"SHELL":   true,
+	"LANG":    true,
+	"TZ":      true,
+	"GOPATH":  true,
+	"GOOS":    true,
+	"GOARCH":  true,
+}
+
+// FuncMap returns a new FuncMap with every built-in template function.
+// Callers may add or override entries on the returned map.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// String manipulation
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      strings.Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": trimPrefix,
+		"trimSuffix": trimSuffix,
+		"replace":    strings.ReplaceAll,
+		"contains":   strings.Contains,
+		"hasPrefix":  strings.HasPrefix,
+		"hasSuffix":  strings.HasSuffix,
+		"repeat":     repeat,
+		"quote":      quote,
+		"squote":     squote,
+
+		// Case conversion
+		"kebab":          Kebab,
+		"snake":          Snake,
+		"camel":          Camel,
+		"pascal":         Pascal,
+		"screamingSnake": ScreamingSnake,
+		"words":          Words,
+
+		// Path manipulation
+		"base":     filepath.Base,
+		"dir":      filepath.Dir,
+		"ext":      filepath.Ext,
+		"pathJoin": filepath.Join,
+
+		// Lists
+		"list":      list,
+		"split":     split,
+		"join":      join,
+		"first":     first,
+		"last":      last,
+		"rest":      rest,
+		"uniq":      uniq,
+		"has":       has,
+		"sortAlpha": sortAlpha,
+		"append":    appendList,
+
+		// Maps
+		"dict":   dict,
+		"keys":   keys,
+		"values": values,
+		"get":    get,
+		"set":    set,
+		"hasKey": hasKey,
+		"merge":  merge,
+
+		// Encoding and formatting
+		"toYaml":       toYaml,
+		"toJson":       toJSON,
+		"toPrettyJson": toPrettyJSON,
+		"fromYaml":     fromYaml,
+		"fromJson":     fromJSON,
+		"indent":       indent,
+		"nindent":      nindent,
+		"b64enc":       b64enc,
+		"b64dec":       b64dec,
+
+		// Hashing and generated values
+		"sha256sum":    sha256sum,
+		"sha1sum":      sha1sum,
+		"uuid":         uuid,
+		"randAlphaNum": randAlphaNum,
+		"randNumeric":  randNumeric,
+
+		// Semantic versions
+		"semverCompare": semverCompare,
+		"semverMajor":   semverMajor,
+		"semverMinor":   semverMinor,
+		"semverPatch":   semverPatch,
+
+		// Utilities
+		"now": func() string {
+			return time.Now().Format("2006-01-02 15:04:05")
+		},
+		"date": func(format string) string {
+			return time.Now().Format(format)
+		},
+		"env":      env,
+		"default":  defaultValue,
+		"empty":    empty,
+		"coalesce": coalesce,
+		"ternary":  ternary,
+	}
+}
+
+// env returns an environment variable templates are allowed to read
+func env(name string) (string, error) {
+	if !safeEnv[name] && !allowedEnv(name) {
+		return "", fmt.Errorf("env: $%s is not readable by templates; list it in $%s to allow it", name, TemplateEnvVar)
+	}
+	return os.Getenv(name), nil
+}
+
+// allowedEnv reports whether the user opted name into env
+func allowedEnv(name string) bool {
+	for _, allowed := range strings.Split(os.Getenv(TemplateEnvVar), ",") {
+		if strings.TrimSpace(allowed) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValue returns value unless it is empty, in which case def is returned
+func defaultValue(def interface{}, value interface{}) interface{} {
+	if empty(value) {
+		return def
+	}
+	return value
+}
+
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !empty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+func ternary(whenTrue, whenFalse interface{}, condition bool) interface{} {
+	if condition {
+		return whenTrue
+	}
+	return whenFalse
+}
+
+// empty reports whether v is nil or the zero value of its type, or an empty collection
+func empty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	}
+	return value.IsZero()
+}
@@ -0,0 +1,182 @@
+package funcs
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		in     string
+		words  []string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{"", nil, "", "", "", ""},
+		{"my-service", []string{"my", "service"}, "myService", "MyService", "my_service", "my-service"},
+		{"my_service name", []string{"my", "service", "name"}, "myServiceName", "MyServiceName", "my_service_name", "my-service-name"},
+		{"myHTTPServer", []string{"my", "HTTP", "Server"}, "myHttpServer", "MyHttpServer", "my_http_server", "my-http-server"},
+		{"HTTPServer", []string{"HTTP", "Server"}, "httpServer", "HttpServer", "http_server", "http-server"},
+		{"parseURL", []string{"parse", "URL"}, "parseUrl", "ParseUrl", "parse_url", "parse-url"},
+		{"userID", []string{"user", "ID"}, "userId", "UserId", "user_id", "user-id"},
+		{"v2Api", []string{"v2", "Api"}, "v2Api", "V2Api", "v2_api", "v2-api"},
+		{"oauth2Client", []string{"oauth2", "Client"}, "oauth2Client", "Oauth2Client", "oauth2_client", "oauth2-client"},
+		{"s3-bucket 42", []string{"s3", "bucket", "42"}, "s3Bucket42", "S3Bucket42", "s3_bucket_42", "s3-bucket-42"},
+		{"  --a--b--  ", []string{"a", "b"}, "aB", "AB", "a_b", "a-b"},
+		{"ÉcoleNormale", []string{"École", "Normale"}, "écoleNormale", "ÉcoleNormale", "école_normale", "école-normale"},
+		{"straßeName", []string{"straße", "Name"}, "straßeName", "StraßeName", "straße_name", "straße-name"},
+		{"日本語 text", []string{"日本語", "text"}, "日本語Text", "日本語Text", "日本語_text", "日本語-text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Words(tt.in); !reflect.DeepEqual(got, tt.words) {
+				t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.words)
+			}
+			if got := Camel(tt.in); got != tt.camel {
+				t.Errorf("Camel(%q) = %q, want %q", tt.in, got, tt.camel)
+			}
+			if got := Pascal(tt.in); got != tt.pascal {
+				t.Errorf("Pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+			}
+			if got := Snake(tt.in); got != tt.snake {
+				t.Errorf("Snake(%q) = %q, want %q", tt.in, got, tt.snake)
+			}
+			if got := Kebab(tt.in); got != tt.kebab {
+				t.Errorf("Kebab(%q) = %q, want %q", tt.in, got, tt.kebab)
+			}
+			if got, want := ScreamingSnake(tt.in), strings.ToUpper(tt.snake); got != want {
+				t.Errorf("ScreamingSnake(%q) = %q, want %q", tt.in, got, want)
+			}
+		})
+	}
+}
+
+// execute renders src with the built-in functions and data
+func execute(t *testing.T, src string, data interface{}) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(FuncMap()).Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", src, err)
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, data)
+	return b.String(), err
+}
+
+func TestTemplateFunctions(t *testing.T) {
+	data := map[string]interface{}{
+		"items":    []string{"b", "a", "b", "c"},
+		"empty":    []string{},
+		"name":     "demo",
+		"dir":      "cmd",
+		"settings": map[string]interface{}{"port": 8080, "host": "localhost"},
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		// Lists
+		{"list join", `{{join ", " (list "a" "b" 3)}}`, "a, b, 3"},
+		{"join typed list", `{{join "-" .items}}`, "b-a-b-c"},
+		{"join path", `{{join "cmd" .name "main.go"}}`, filepath.Join("cmd", "demo", "main.go")},
+		{"join two strings", `{{join .dir .name}}`, filepath.Join("cmd", "demo")},
+		{"pathJoin", `{{pathJoin "a" "b"}}`, filepath.Join("a", "b")},
+		{"split", `{{range split "," " users, orders,,"}}[{{.}}]{{end}}`, "[users][orders]"},
+		{"first last rest", `{{first .items}} {{last .items}} {{rest .items}}`, "b c [a b c]"},
+		{"first of empty", `{{first .empty}}`, "<no value>"},
+		{"uniq", `{{uniq .items}}`, "[b a c]"},
+		{"has", `{{has "a" .items}} {{has "z" .items}}`, "true false"},
+		{"sortAlpha", `{{sortAlpha .items}}`, "[a b b c]"},
+		{"append", `{{append .items "d"}}`, "[b a b c d]"},
+
+		// Maps
+		{"dict keys values", `{{$d := dict "b" 2 "a" 1}}{{keys $d}} {{values $d}}`, "[a b] [1 2]"},
+		{"get hasKey", `{{get .settings "port"}} {{get .settings "x"}} {{hasKey .settings "host"}}`, "8080  true"},
+		{"set", `{{$d := dict}}{{$_ := set $d "k" "v"}}{{get $d "k"}}`, "v"},
+		{"merge", `{{$m := merge (dict "a" 1) (dict "a" 2 "b" 3)}}{{get $m "a"}}{{get $m "b"}}`, "13"},
+
+		// Encoding and formatting
+		{"toYaml", `{{toYaml .settings}}`, "host: localhost\nport: 8080"},
+		{"nindent", `x:{{toYaml .settings | nindent 2}}`, "x:\n  host: localhost\n  port: 8080"},
+		{"toJson", `{{toJson .settings}}`, `{"host":"localhost","port":8080}`},
+		{"fromJson", `{{get (fromJson "{\"a\":\"b\"}") "a"}}`, "b"},
+		{"fromYaml", `{{get (fromYaml "a: b") "a"}}`, "b"},
+		{"b64", `{{b64enc "héllo"}} {{b64dec (b64enc "héllo")}}`, "aMOpbGxv héllo"},
+		{"sha256sum", `{{sha256sum "abc"}}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"sha1sum", `{{sha1sum "abc"}}`, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"quote", `{{quote "a\"b" 1}} {{squote "x"}}`, `"a\"b" "1" 'x'`},
+		{"randAlphaNum length", `{{len (randAlphaNum 32)}}`, "32"},
+		{"uuid length", `{{len uuid}}`, "36"},
+
+		// Semantic versions
+		{"semver range", `{{semverCompare ">=1.21, <2.0" "1.22.3"}}`, "true"},
+		{"semver range miss", `{{semverCompare ">=1.21, <2.0" "2.0.0"}}`, "false"},
+		{"semver operator space", `{{semverCompare ">= 1.2" "v1.2.0"}}`, "true"},
+		{"semver or", `{{semverCompare "^1.2 || ~2.0.1" "2.0.5"}}`, "true"},
+		{"semver caret zero", `{{semverCompare "^0.2" "0.3.0"}}`, "false"},
+		{"semver tilde", `{{semverCompare "~1.2.0" "1.3.0"}}`, "false"},
+		{"semver prerelease", `{{semverCompare "<1.0.0" "1.0.0-rc.1"}}`, "true"},
+		{"semver prerelease order", `{{semverCompare ">1.0.0-alpha.2" "1.0.0-alpha.10"}}`, "true"},
+		{"semver parts", `{{semverMajor "v1.22.3+build"}}.{{semverMinor "1.22.3"}}.{{semverPatch "1.22.3-rc"}}`, "1.22.3"},
+
+		// Utilities
+		{"default", `{{default "x" ""}} {{default "x" "y"}}`, "x y"},
+		{"coalesce", `{{coalesce "" .missing "z"}}`, "z"},
+		{"ternary", `{{ternary "yes" "no" true}}`, "yes"},
+		{"empty", `{{empty .empty}} {{empty .items}}`, "true false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := execute(t, tt.src, data)
+			if err != nil {
+				t.Fatalf("execute(%q): %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("execute(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFunctionErrors(t *testing.T) {
+	for _, src := range []string{
+		`{{dict "a"}}`,
+		`{{first "not a list"}}`,
+		`{{keys "not a map"}}`,
+		`{{semverCompare ">=1.x" "1.0.0"}}`,
+		`{{semverMajor "latest"}}`,
+		`{{b64dec "%%%"}}`,
+		`{{join "a" 1}}`,
+	} {
+		if _, err := execute(t, src, nil); err == nil {
+			t.Errorf("execute(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/demo")
+	t.Setenv("API_TOKEN", "secret")
+	t.Setenv(TemplateEnvVar, "")
+
+	if got, err := execute(t, `{{env "HOME"}}`, nil); err != nil || got != "/home/demo" {
+		t.Errorf(`env "HOME" = %q, %v; want /home/demo`, got, err)
+	}
+	if got, err := execute(t, `{{env "API_TOKEN"}}`, nil); err == nil {
+		t.Errorf(`env "API_TOKEN" = %q, want an error`, got)
+	}
+
+	t.Setenv(TemplateEnvVar, "CI, API_TOKEN")
+	if got, err := execute(t, `{{env "API_TOKEN"}}`, nil); err != nil || got != "secret" {
+		t.Errorf(`env "API_TOKEN" with opt-in = %q, %v; want secret`, got, err)
+	}
+}
@@ -0,0 +1,191 @@
+package funcs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a parsed semantic version
+type version struct {
+	major, minor, patch int64
+	pre                 []string
+}
+
+func parseVersion(s string) (version, error) {
+	var v version
+
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(raw, '+'); i >= 0 {
+		raw = raw[:i]
+	}
+	if i := strings.IndexByte(raw, '-'); i >= 0 {
+		v.pre = strings.Split(raw[i+1:], ".")
+		raw = raw[:i]
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) == 0 || len(parts) > 3 || parts[0] == "" {
+		return v, fmt.Errorf("invalid semantic version '%s'", s)
+	}
+
+	numbers := [3]int64{}
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid semantic version '%s'", s)
+		}
+		numbers[i] = n
+	}
+	v.major, v.minor, v.patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+// compare returns -1, 0 or 1 following semver precedence rules
+func (v version) compare(o version) int {
+	for _, pair := range [][2]int64{{v.major, o.major}, {v.minor, o.minor}, {v.patch, o.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A version without pre-release identifiers has higher precedence
+	switch {
+	case len(v.pre) == 0 && len(o.pre) == 0:
+		return 0
+	case len(v.pre) == 0:
+		return 1
+	case len(o.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.pre) && i < len(o.pre); i++ {
+		if c := comparePreRelease(v.pre[i], o.pre[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(v.pre) < len(o.pre):
+		return -1
+	case len(v.pre) > len(o.pre):
+		return 1
+	}
+	return 0
+}
+
+func comparePreRelease(a, b string) int {
+	an, aErr := strconv.ParseInt(a, 10, 64)
+	bn, bErr := strconv.ParseInt(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an < bn:
+			return -1
+		case an > bn:
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// semverCompare reports whether ver satisfies the constraint.
+// Constraints are comparisons (=, !=, >, >=, <, <=, ^, ~) joined by commas
+// or spaces (AND) and "||" (OR), e.g. ">=1.21, <2.0" or "^1.2 || ~2.0.1".
+func semverCompare(constraint, ver string) (bool, error) {
+	v, err := parseVersion(ver)
+	if err != nil {
+		return false, err
+	}
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		satisfied := true
+		clauses := strings.FieldsFunc(alternative, func(r rune) bool { return r == ',' || r == ' ' })
+		if len(clauses) == 0 {
+			return false, fmt.Errorf("empty semver constraint")
+		}
+
+		for i := 0; i < len(clauses); i++ {
+			clause := clauses[i]
+			// Allow a space between the operator and the version (">= 1.2")
+			if strings.Trim(clause, "=!<>^~") == "" && i+1 < len(clauses) {
+				clause += clauses[i+1]
+				i++
+			}
+
+			ok, err := matchClause(clause, v)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				satisfied = false
+			}
+		}
+
+		if satisfied {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func matchClause(clause string, v version) (bool, error) {
+	op := strings.TrimRight(clause[:len(clause)-len(strings.TrimLeft(clause, "=!<>^~"))], " ")
+	target, err := parseVersion(clause[len(op):])
+	if err != nil {
+		return false, err
+	}
+
+	c := v.compare(target)
+	switch op {
+	case "", "=", "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case "^":
+		// Same major version (same minor for 0.x)
+		if c < 0 || v.major != target.major {
+			return false, nil
+		}
+		return target.major != 0 || v.minor == target.minor, nil
+	case "~":
+		// Same major and minor version
+		return c >= 0 && v.major == target.major && v.minor == target.minor, nil
+	}
+
+	return false, fmt.Errorf("unsupported semver operator '%s'", op)
+}
+
+func semverMajor(ver string) (int64, error) {
+	v, err := parseVersion(ver)
+	return v.major, err
+}
+
+func semverMinor(ver string) (int64, error) {
+	v, err := parseVersion(ver)
+	return v.minor, err
+}
+
+func semverPatch(ver string) (int64, error) {
+	v, err := parseVersion(ver)
+	return v.patch, err
+}
@@ -0,0 +1,154 @@
+package funcs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Words splits s into words on spaces, punctuation, underscores and hyphens,
+// and on case changes so that "myHTTPServer" becomes [my HTTP Server]
+func Words(s string) []string {
+	var words []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if len(current) > 0 && unicode.IsUpper(r) {
+			prev := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// "myService" -> my|Service, "v2Api" -> v2|Api, "HTTPServer" -> HTTP|Server
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// Kebab converts s to kebab-case
+func Kebab(s string) string {
+	return joinWords(s, "-", strings.ToLower)
+}
+
+// Snake converts s to snake_case
+func Snake(s string) string {
+	return joinWords(s, "_", strings.ToLower)
+}
+
+// ScreamingSnake converts s to SCREAMING_SNAKE_CASE
+func ScreamingSnake(s string) string {
+	return joinWords(s, "_", strings.ToUpper)
+}
+
+// Camel converts s to camelCase
+func Camel(s string) string {
+	words := Words(s)
+	if len(words) == 0 {
+		return s
+	}
+
+	var result strings.Builder
+	result.WriteString(strings.ToLower(words[0]))
+	for _, word := range words[1:] {
+		result.WriteString(capitalize(word))
+	}
+	return result.String()
+}
+
+// Pascal converts s to PascalCase
+func Pascal(s string) string {
+	var result strings.Builder
+	for _, word := range Words(s) {
+		result.WriteString(capitalize(word))
+	}
+	return result.String()
+}
+
+func joinWords(s, separator string, transform func(string) string) string {
+	words := Words(s)
+	for i, word := range words {
+		words[i] = transform(word)
+	}
+	return strings.Join(words, separator)
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func trimPrefix(prefix, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+func trimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+func repeat(count int, s string) string {
+	if count < 0 {
+		count = 0
+	}
+	return strings.Repeat(s, count)
+}
+
+// quote wraps each argument in double quotes, escaping as Go string literals
+func quote(args ...interface{}) string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%q", toString(arg)))
+	}
+	return strings.Join(out, " ")
+}
+
+// squote wraps each argument in single quotes without escaping
+func squote(args ...interface{}) string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		out = append(out, "'"+toString(arg)+"'")
+	}
+	return strings.Join(out, " ")
+}
+
+func toString(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case fmt.Stringer:
+		return value.String()
+	case error:
+		return value.Error()
+	}
+	return fmt.Sprint(v)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
 )
 
 // ProjectConfig holds the configuration for generating a new project
@@ -72,6 +74,55 @@ func (c *ProjectConfig) Validate() error {
 	return nil
 }
 
+// ApplyProfile fills configuration fields that are still unset with profile defaults
+func (c *ProjectConfig) ApplyProfile(p *types.Profile) {
+	if p == nil {
+		return
+	}
+
+	if c.Module == "" && c.Name != "" && p.ModulePrefix != "" {
+		c.Module = strings.TrimSuffix(p.ModulePrefix, "/") + "/" + c.Name
+	}
+	if c.Database == "" {
+		c.Database = p.Project.Database
+	}
+	if c.Cache == "" {
+		c.Cache = p.Project.Cache
+	}
+	if c.Queue == "" {
+		c.Queue = p.Project.Queue
+	}
+	if c.CI == "" {
+		c.CI = p.Project.CI
+	}
+	if len(c.Monitoring) == 0 {
+		c.Monitoring = p.Project.Monitoring
+	}
+	if len(c.Features) == 0 && len(p.Project.Features) > 0 {
+		c.Features = p.Project.Features
+	}
+	if p.Project.Docker != nil {
+		c.Docker = *p.Project.Docker
+	}
+	if p.Project.Kubernetes != nil {
+		c.Kubernetes = *p.Project.Kubernetes
+	}
+
+	if c.Custom == nil {
+		c.Custom = make(map[string]string)
+	}
+	for _, pv := range p.PlaceholderValues() {
+		if _, exists := c.Custom[pv.Name]; !exists {
+			c.Custom[pv.Name] = pv.Value
+		}
+	}
+	for name, value := range p.Project.Custom {
+		if _, exists := c.Custom[name]; !exists {
+			c.Custom[name] = value
+		}
+	}
+}
+
 // ToTemplateVars converts the config to template variables
 func (c *ProjectConfig) ToTemplateVars() *TemplateVars {
 	packageName := strings.ReplaceAll(c.Name, "-", "")
@@ -121,18 +172,6 @@ func FromJSON(jsonStr string) (*ProjectConfig, error) {
 	return config, nil
 }
 
-// SupportedTemplates returns the list of supported templates
-func SupportedTemplates() []string {
-	return []string{
-		"api-rest",
-		"cli-tool",
-		"microservice",
-		"grpc-service",
-		"worker",
-		"library",
-	}
-}
-
 // SupportedDatabases returns the list of supported databases
 func SupportedDatabases() []string {
 	return []string{
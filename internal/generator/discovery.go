@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/engine"
+)
+
+// TemplateRoot is a filesystem holding one directory per template
+type TemplateRoot struct {
+	Name string // Where the templates come from, e.g. a directory or "built-in"
+	FS   fs.FS
+}
+
+// DiscoveredTemplate is a usable template found in a template root
+type DiscoveredTemplate struct {
+	Name string
+	Root TemplateRoot // Template root the template was found in
+	Path string       // Location for display, e.g. "built-in/api-rest"
+	Info *TemplateInfo
+}
+
+// InvalidTemplate is a template directory that can't be generated from
+type InvalidTemplate struct {
+	Name string
+	Path string
+	Err  error
+}
+
+func (t *InvalidTemplate) Error() string {
+	return fmt.Sprintf("template '%s' (%s) is invalid: %v", t.Name, t.Path, t.Err)
+}
+
+// TemplateCatalog holds the templates found by scanning template roots
+type TemplateCatalog struct {
+	Templates []*DiscoveredTemplate // Sorted by name
+	Invalid   []*InvalidTemplate    // Sorted by name
+}
+
+// DiscoverTemplates scans roots in precedence order. Every directory in a
+// root is a template; a name found in an earlier root shadows later ones.
+func DiscoverTemplates(roots []TemplateRoot) *TemplateCatalog {
+	catalog := &TemplateCatalog{}
+	seen := make(map[string]bool)
+
+	for _, root := range roots {
+		entries, err := fs.ReadDir(root.FS, ".")
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			templatePath := path.Join(root.Name, name)
+			info, err := inspectTemplate(root.FS, name)
+			if err != nil {
+				catalog.Invalid = append(catalog.Invalid, &InvalidTemplate{Name: name, Path: templatePath, Err: err})
+				continue
+			}
+			catalog.Templates = append(catalog.Templates, &DiscoveredTemplate{
+				Name: name,
+				Root: root,
+				Path: templatePath,
+				Info: info,
+			})
+		}
+	}
+
+	sort.Slice(catalog.Templates, func(i, j int) bool { return catalog.Templates[i].Name < catalog.Templates[j].Name })
+	sort.Slice(catalog.Invalid, func(i, j int) bool { return catalog.Invalid[i].Name < catalog.Invalid[j].Name })
+	return catalog
+}
+
+// Names returns the names of the usable templates
+func (c *TemplateCatalog) Names() []string {
+	names := make([]string, len(c.Templates))
+	for i, tmpl := range c.Templates {
+		names[i] = tmpl.Name
+	}
+	return names
+}
+
+// Get returns a usable template, explaining why an invalid one can't be used
+func (c *TemplateCatalog) Get(name string) (*DiscoveredTemplate, error) {
+	for _, tmpl := range c.Templates {
+		if tmpl.Name == name {
+			return tmpl, nil
+		}
+	}
+	for _, invalid := range c.Invalid {
+		if invalid.Name == name {
+			return nil, invalid
+		}
+	}
+	return nil, fmt.Errorf("template '%s' not found. Run 'gocrafter list' to see available templates", name)
+}
+
+// inspectTemplate reads and checks a template's metadata, filling defaults
+// for templates without a template.json
+func inspectTemplate(templates fs.FS, name string) (*TemplateInfo, error) {
+	info, err := loadTemplateInfo(templates, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Name == "" {
+		info.Name = name
+	} else if info.Name != name {
+		return nil, fmt.Errorf("%s names the template '%s' but its directory is '%s'", templateMetadataFile, info.Name, name)
+	}
+	if info.Description == "" {
+		info.Description = fmt.Sprintf("Template for %s projects", name)
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+
+	if _, err := engine.NewFileRules(info.Conditions); err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+
+	hasFiles, err := templateHasFiles(templates, name)
+	if err != nil {
+		return nil, err
+	}
+	if !hasFiles {
+		return nil, fmt.Errorf("template has no files to generate")
+	}
+
+	return info, nil
+}
+
+// templateHasFiles reports whether the template produces at least one file
+func templateHasFiles(templates fs.FS, templateDir string) (bool, error) {
+	found := false
+	err := fs.WalkDir(templates, templateDir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(name, templateDir+"/")
+		if d.IsDir() {
+			if rel == engine.PartialsDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if rel != templateMetadataFile {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// templateRoot returns a template root holding the given files
+func templateRoot(name string, files map[string]string) TemplateRoot {
+	fsys := fstest.MapFS{}
+	for path, content := range files {
+		fsys[path] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return TemplateRoot{Name: name, FS: fsys}
+}
+
+func TestDiscoverTemplates(t *testing.T) {
+	catalog := DiscoverTemplates([]TemplateRoot{
+		templateRoot("project", map[string]string{
+			"api/template.json":    `{"description": "project api"}`,
+			"api/main.go":          "package main",
+			"broken/README.md":     "# {{project_name}}",
+			"broken/template.json": `{"name": "other"}`,
+		}),
+		templateRoot("user", map[string]string{
+			"api/main.go":              "package main",
+			"broken/README.md":         "# {{project_name}}",
+			"cli/main.go":              "package main",
+			"empty/template.json":      `{}`,
+			"empty/partials/header.md": "header",
+			".hidden/main.go":          "package main",
+			"notes.txt":                "not a template",
+		}),
+		templateRoot("built-in", map[string]string{
+			"api/main.go":    "package main",
+			"cli/main.go":    "package main",
+			"worker/main.go": "package main",
+		}),
+	})
+
+	if names := catalog.Names(); !reflect.DeepEqual(names, []string{"api", "cli", "worker"}) {
+		t.Errorf("Names = %v", names)
+	}
+
+	tests := []struct {
+		name string
+		path string // Location of the template found; empty when invalid
+		desc string
+	}{
+		{"api", "project/api", "project api"},
+		{"cli", "user/cli", "Template for cli projects"},
+		{"worker", "built-in/worker", "Template for worker projects"},
+		// An invalid template still shadows valid ones in later roots
+		{"broken", "", ""},
+		{"empty", "", ""},
+		{".hidden", "", ""},
+		{"missing", "", ""},
+	}
+	for _, tt := range tests {
+		tmpl, err := catalog.Get(tt.name)
+		if tt.path == "" {
+			if err == nil {
+				t.Errorf("Get(%q) found %s", tt.name, tmpl.Path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.name, err)
+			continue
+		}
+		if tmpl.Path != tt.path || tmpl.Info.Description != tt.desc || tmpl.Info.Version != "1.0.0" {
+			t.Errorf("Get(%q) = %s %q %s, want %s %q", tt.name, tmpl.Path, tmpl.Info.Description, tmpl.Info.Version, tt.path, tt.desc)
+		}
+	}
+
+	var invalid []string
+	for _, tmpl := range catalog.Invalid {
+		invalid = append(invalid, tmpl.Path)
+	}
+	if want := []string{"project/broken", "user/empty"}; !reflect.DeepEqual(invalid, want) {
+		t.Errorf("Invalid = %v, want %v", invalid, want)
+	}
+}
@@ -0,0 +1,474 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+const (
+	// downloadsDir is the cache directory holding downloaded kits
+	downloadsDir = "downloads"
+	// downloadIndexFile maps the URLs in the download cache to their objects
+	downloadIndexFile = "index.json"
+	// archivesDir holds archives, named by the SHA-256 of their contents
+	archivesDir = "archives"
+	// mirrorsDir holds bare git mirrors, named by the hash of their URL
+	mirrorsDir = "git"
+	// downloadTimeout bounds an archive download, so a stalled server can't hang kit add
+	downloadTimeout = 5 * time.Minute
+)
+
+// Kinds of download cache entries
+const (
+	DownloadArchive = "archive"
+	DownloadGit     = "git"
+)
+
+// ErrNotCached is returned offline for a URL missing from the download cache
+var ErrNotCached = errors.New("not in the download cache")
+
+// DownloadEntry is a URL in the download cache
+type DownloadEntry struct {
+	URL     string    `json:"url"`
+	Kind    string    `json:"kind"`   // DownloadArchive or DownloadGit
+	Object  string    `json:"object"` // Path of the archive or mirror, relative to the cache
+	Ref     string    `json:"ref"`    // ETag or Last-Modified of an archive, HEAD commit of a mirror
+	Size    int64     `json:"size"`
+	Fetched time.Time `json:"fetched"`
+	Used    time.Time `json:"used"`
+}
+
+// DownloadCache keeps fetched kit archives and git mirrors, so adding or
+// updating a kit only downloads what changed. Entries unused for longer than
+// maxAge, or the least recently used ones beyond maxSize, are evicted.
+type DownloadCache struct {
+	dir     string
+	maxAge  time.Duration // No age limit if zero
+	maxSize int64         // No size limit if zero
+	entries map[string]*DownloadEntry
+}
+
+// NewDownloadCache returns the download cache kept in cacheDir
+func NewDownloadCache(cacheDir string, maxAge time.Duration, maxSize int64) *DownloadCache {
+	return &DownloadCache{
+		dir:     filepath.Join(cacheDir, downloadsDir),
+		maxAge:  maxAge,
+		maxSize: maxSize,
+	}
+}
+
+// Dir returns the directory of the download cache
+func (c *DownloadCache) Dir() string {
+	return c.dir
+}
+
+// Limits returns the age and size limits entries are evicted by; zero is no limit
+func (c *DownloadCache) Limits() (time.Duration, int64) {
+	return c.maxAge, c.maxSize
+}
+
+// Entries returns the cached URLs, most recently used first
+func (c *DownloadCache) Entries() ([]DownloadEntry, error) {
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]DownloadEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Used.After(entries[j].Used)
+	})
+	return entries, nil
+}
+
+// Size returns the disk space used by the cached objects
+func (c *DownloadCache) Size() (int64, error) {
+	if err := c.load(); err != nil {
+		return 0, err
+	}
+	return c.totalSize(), nil
+}
+
+// Archive returns the path of the cached archive downloaded from url. The
+// server is asked whether the archive changed since it was cached; it is only
+// downloaded again if so. Offline, or when the server can't be reached, the
+// cached archive is used as is.
+func (c *DownloadCache) Archive(url string, offline bool) (string, error) {
+	if err := c.load(); err != nil {
+		return "", err
+	}
+
+	entry := c.cached(url, DownloadArchive)
+	if offline {
+		if entry == nil {
+			return "", fmt.Errorf("%s: %w", url, ErrNotCached)
+		}
+		return c.use(entry)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to download archive: %w", err)
+	}
+	if entry != nil && entry.Ref != "" {
+		if strings.HasPrefix(entry.Ref, `"`) || strings.HasPrefix(entry.Ref, `W/"`) {
+			req.Header.Set("If-None-Match", entry.Ref)
+		} else {
+			req.Header.Set("If-Modified-Since", entry.Ref)
+		}
+	}
+
+	client := &http.Client{Timeout: downloadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		if entry != nil {
+			gl.Log("warn", fmt.Sprintf("Using cached archive of %s: %v", url, err))
+			return c.use(entry)
+		}
+		return "", fmt.Errorf("failed to download archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		return c.use(entry)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download archive: HTTP %d", resp.StatusCode)
+	}
+
+	object, size, err := c.storeArchive(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	ref := resp.Header.Get("ETag")
+	if ref == "" {
+		ref = resp.Header.Get("Last-Modified")
+	}
+	return c.put(&DownloadEntry{URL: url, Kind: DownloadArchive, Object: object, Ref: ref, Size: size})
+}
+
+// Mirror returns the path of an up-to-date bare git mirror of url. The
+// mirror is only fetched when the remote HEAD moved since it was cached.
+// Offline, or when the remote can't be reached, the cached mirror is used as
+// is.
+func (c *DownloadCache) Mirror(url string, offline bool) (string, error) {
+	if err := c.load(); err != nil {
+		return "", err
+	}
+
+	entry := c.cached(url, DownloadGit)
+	if offline {
+		if entry == nil {
+			return "", fmt.Errorf("%s: %w", url, ErrNotCached)
+		}
+		return c.use(entry)
+	}
+
+	if entry != nil {
+		mirror := filepath.Join(c.dir, entry.Object)
+		head, err := remoteHead(url)
+		if err != nil {
+			gl.Log("warn", fmt.Sprintf("Using cached mirror of %s: %v", url, err))
+			return c.use(entry)
+		}
+		if head == entry.Ref {
+			return c.use(entry)
+		}
+		if err := runGit("", "--git-dir", mirror, "remote", "update", "--prune"); err != nil {
+			return "", fmt.Errorf("failed to update mirror: %w", err)
+		}
+		entry.Ref = head
+		entry.Size = dirSize(mirror)
+		return c.put(entry)
+	}
+
+	object := filepath.Join(mirrorsDir, hashString(url)+".git")
+	mirror := filepath.Join(c.dir, object)
+	tmp := mirror + ".tmp"
+	os.RemoveAll(tmp)
+	if err := os.MkdirAll(filepath.Dir(mirror), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := runGit("", "clone", "--quiet", "--mirror", url, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("git clone failed: %w", err)
+	}
+	os.RemoveAll(mirror)
+	if err := os.Rename(tmp, mirror); err != nil {
+		return "", fmt.Errorf("failed to store mirror: %w", err)
+	}
+
+	head, err := gitOutput("--git-dir", mirror, "rev-parse", "HEAD")
+	if err != nil {
+		head = ""
+	}
+	return c.put(&DownloadEntry{URL: url, Kind: DownloadGit, Object: object, Ref: head, Size: dirSize(mirror)})
+}
+
+// Prune evicts the entries unused for longer than the age limit, then the
+// least recently used ones until the cache fits the size limit, and removes
+// objects no entry refers to. It returns the evicted entries.
+func (c *DownloadCache) Prune() ([]DownloadEntry, error) {
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	removed := c.evict("")
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	c.removeOrphans()
+	return removed, nil
+}
+
+// Clear removes every entry and object
+func (c *DownloadCache) Clear() error {
+	c.entries = nil
+	if err := os.RemoveAll(c.dir); err != nil {
+		return fmt.Errorf("failed to clear download cache: %w", err)
+	}
+	return nil
+}
+
+// cached returns the entry of url if its object is still on disk
+func (c *DownloadCache) cached(url, kind string) *DownloadEntry {
+	entry, ok := c.entries[url]
+	if !ok || entry.Kind != kind {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(c.dir, entry.Object)); err != nil {
+		return nil
+	}
+	return entry
+}
+
+// use marks an entry as used and returns the path of its object
+func (c *DownloadCache) use(entry *DownloadEntry) (string, error) {
+	entry.Used = time.Now()
+	if err := c.save(); err != nil {
+		return "", err
+	}
+	return filepath.Join(c.dir, entry.Object), nil
+}
+
+// put records a freshly fetched entry, evicting others if the cache is now
+// over its limits, and returns the path of its object
+func (c *DownloadCache) put(entry *DownloadEntry) (string, error) {
+	entry.Fetched = time.Now()
+	entry.Used = entry.Fetched
+	c.entries[entry.URL] = entry
+	for _, evicted := range c.evict(entry.URL) {
+		gl.Log("info", fmt.Sprintf("Evicted %s from the download cache", evicted.URL))
+	}
+	if err := c.save(); err != nil {
+		return "", err
+	}
+	c.removeOrphans()
+	return filepath.Join(c.dir, entry.Object), nil
+}
+
+// evict drops entries over the cache limits, never the entry of keep
+func (c *DownloadCache) evict(keep string) []DownloadEntry {
+	var removed []DownloadEntry
+	if c.maxAge > 0 {
+		for url, entry := range c.entries {
+			if url != keep && time.Since(entry.Used) > c.maxAge {
+				removed = append(removed, *entry)
+				delete(c.entries, url)
+			}
+		}
+	}
+
+	if c.maxSize > 0 && c.totalSize() > c.maxSize {
+		lru := make([]*DownloadEntry, 0, len(c.entries))
+		for url, entry := range c.entries {
+			if url != keep {
+				lru = append(lru, entry)
+			}
+		}
+		sort.Slice(lru, func(i, j int) bool {
+			return lru[i].Used.Before(lru[j].Used)
+		})
+		for _, entry := range lru {
+			if c.totalSize() <= c.maxSize {
+				break
+			}
+			removed = append(removed, *entry)
+			delete(c.entries, entry.URL)
+		}
+	}
+	return removed
+}
+
+// totalSize sums the sizes of the objects, counting shared archives once
+func (c *DownloadCache) totalSize() int64 {
+	var total int64
+	counted := make(map[string]bool)
+	for _, entry := range c.entries {
+		if !counted[entry.Object] {
+			counted[entry.Object] = true
+			total += entry.Size
+		}
+	}
+	return total
+}
+
+// removeOrphans deletes archives and mirrors no entry refers to
+func (c *DownloadCache) removeOrphans() {
+	referenced := make(map[string]bool)
+	for _, entry := range c.entries {
+		referenced[entry.Object] = true
+	}
+	for _, dir := range []string{archivesDir, mirrorsDir} {
+		objects, err := os.ReadDir(filepath.Join(c.dir, dir))
+		if err != nil {
+			continue
+		}
+		for _, object := range objects {
+			name := filepath.Join(dir, object.Name())
+			if !referenced[name] {
+				os.RemoveAll(filepath.Join(c.dir, name))
+			}
+		}
+	}
+}
+
+// storeArchive writes an archive under the hash of its contents and returns
+// its path relative to the cache and its size. Identical archives downloaded
+// from different URLs are stored once.
+func (c *DownloadCache) storeArchive(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(filepath.Join(c.dir, archivesDir), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, archivesDir), ".download-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to cache archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download archive: %w", err)
+	}
+
+	object := filepath.Join(archivesDir, hex.EncodeToString(h.Sum(nil))+".tar.gz")
+	if err := os.Rename(tmp.Name(), filepath.Join(c.dir, object)); err != nil {
+		return "", 0, fmt.Errorf("failed to cache archive: %w", err)
+	}
+	return object, size, nil
+}
+
+// load reads the cache index once; a missing index is an empty cache
+func (c *DownloadCache) load() error {
+	if c.entries != nil {
+		return nil
+	}
+	c.entries = make(map[string]*DownloadEntry)
+
+	data, err := os.ReadFile(filepath.Join(c.dir, downloadIndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read download cache: %w", err)
+	}
+
+	var entries []*DownloadEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		gl.Log("warn", fmt.Sprintf("Ignoring corrupt download cache index: %v", err))
+		return nil
+	}
+	for _, entry := range entries {
+		c.entries[entry.URL] = entry
+	}
+	return nil
+}
+
+// save writes the cache index
+func (c *DownloadCache) save() error {
+	entries := make([]*DownloadEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].URL < entries[j].URL
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, downloadIndexFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write download cache: %w", err)
+	}
+	return nil
+}
+
+// remoteHead returns the commit HEAD points to in the repository at url
+func remoteHead(url string) (string, error) {
+	out, err := gitOutput("ls-remote", url, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no HEAD in %s", url)
+	}
+	return fields[0], nil
+}
+
+// gitOutput runs git and returns its trimmed output; errors include stderr
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// dirSize returns the total size of the files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
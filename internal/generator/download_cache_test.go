@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDownloadCacheArchive(t *testing.T) {
+	var downloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Write([]byte("archive contents"))
+	}))
+	defer server.Close()
+
+	cache := NewDownloadCache(t.TempDir(), 0, 0)
+	first, err := cache.Archive(server.URL+"/a.tar.gz", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := cache.Archive(server.URL+"/a.tar.gz", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || downloads != 1 {
+		t.Errorf("unchanged archive: paths %q, %q after %d downloads; want one download", first, second, downloads)
+	}
+
+	// Identical archives from another URL share the object
+	mirror, err := cache.Archive(server.URL+"/b.tar.gz", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirror != first {
+		t.Errorf("identical archive stored at %q, want %q", mirror, first)
+	}
+	if size, _ := cache.Size(); size != int64(len("archive contents")) {
+		t.Errorf("Size = %d, want the shared archive counted once", size)
+	}
+
+	if _, err := cache.Archive(server.URL+"/c.tar.gz", true); !errors.Is(err, ErrNotCached) {
+		t.Errorf("offline Archive of an uncached URL = %v, want ErrNotCached", err)
+	}
+	server.Close()
+	if path, err := cache.Archive(server.URL+"/a.tar.gz", false); err != nil || path != first {
+		t.Errorf("Archive with the server down = %q, %v; want the cached archive", path, err)
+	}
+}
+
+// cachedObject describes an archive in a download cache fixture
+type cachedObject struct {
+	size int64
+	used time.Duration // Time since the entry was used
+}
+
+// fillCache stores one archive per URL, with the given size and last use
+func fillCache(t *testing.T, cache *DownloadCache, entries map[string]cachedObject) {
+	t.Helper()
+	if err := cache.load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(cache.Dir(), archivesDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for url, e := range entries {
+		object := filepath.Join(archivesDir, url+".tar.gz")
+		if err := os.WriteFile(filepath.Join(cache.Dir(), object), make([]byte, e.size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		used := time.Now().Add(-e.used)
+		cache.entries[url] = &DownloadEntry{URL: url, Kind: DownloadArchive, Object: object, Size: e.size, Fetched: used, Used: used}
+	}
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDownloadCachePrune(t *testing.T) {
+	entries := map[string]cachedObject{
+		"old":    {10, 48 * time.Hour},
+		"recent": {60, time.Hour},
+		"older":  {60, 2 * time.Hour},
+		"new":    {30, 0},
+	}
+
+	tests := []struct {
+		name    string
+		maxAge  time.Duration
+		maxSize int64
+		evicted []string
+	}{
+		{"no limits", 0, 0, nil},
+		{"age", 24 * time.Hour, 0, []string{"old"}},
+		{"size evicts least recently used", 0, 100, []string{"old", "older"}},
+		{"age then size", 24 * time.Hour, 100, []string{"old", "older"}},
+		{"size fits after age", 24 * time.Hour, 150, []string{"old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fillCache(t, NewDownloadCache(dir, 0, 0), entries)
+			orphan := filepath.Join(dir, downloadsDir, archivesDir, "orphan.tar.gz")
+			if err := os.WriteFile(orphan, []byte("x"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// A fresh cache reads the index back, as a new run would
+			cache := NewDownloadCache(dir, tt.maxAge, tt.maxSize)
+			removed, err := cache.Prune()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var evicted []string
+			for _, entry := range removed {
+				evicted = append(evicted, entry.URL)
+				if _, err := os.Stat(filepath.Join(cache.Dir(), entry.Object)); !os.IsNotExist(err) {
+					t.Errorf("object of evicted %s still on disk", entry.URL)
+				}
+			}
+			sort.Strings(evicted)
+			if !reflect.DeepEqual(evicted, tt.evicted) {
+				t.Errorf("Prune evicted %v, want %v", evicted, tt.evicted)
+			}
+
+			if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+				t.Error("Prune kept an object no entry refers to")
+			}
+			left, _ := NewDownloadCache(dir, 0, 0).Entries()
+			if len(left) != len(entries)-len(tt.evicted) {
+				t.Errorf("index keeps %d entries after Prune, want %d", len(left), len(entries)-len(tt.evicted))
+			}
+		})
+	}
+}
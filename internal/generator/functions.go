@@ -0,0 +1,282 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkjson"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// KitFunctionsDir is the kit directory holding Starlark template function scripts
+	KitFunctionsDir = "functions"
+	// DefaultFunctionTimeout bounds the execution time of a single kit function call
+	DefaultFunctionTimeout = time.Second
+	// maxFunctionSteps bounds the number of Starlark computation steps per call
+	maxFunctionSteps = 10_000_000
+)
+
+// KitFunctionLoader loads kit-declared template functions from Starlark scripts.
+// Scripts run in a sandbox: there are no file system, network or environment
+// builtins, `load` is disabled and every call is time and step limited.
+type KitFunctionLoader struct {
+	timeout  time.Duration
+	reserved template.FuncMap
+}
+
+// NewKitFunctionLoader creates a loader that refuses to shadow the reserved functions
+func NewKitFunctionLoader(reserved template.FuncMap, timeout time.Duration) *KitFunctionLoader {
+	if timeout <= 0 {
+		timeout = DefaultFunctionTimeout
+	}
+	return &KitFunctionLoader{
+		timeout:  timeout,
+		reserved: reserved,
+	}
+}
+
+// Load reads every *.star script in the functions directory of the kit
+// filesystem and returns a FuncMap with one entry per public top-level function
+func (l *KitFunctionLoader) Load(kitFS fs.FS) (template.FuncMap, error) {
+	entries, err := fs.ReadDir(kitFS, KitFunctionsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return template.FuncMap{}, nil
+		}
+		return nil, fmt.Errorf("failed to read functions directory: %w", err)
+	}
+
+	funcs := make(template.FuncMap)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".star" {
+			continue
+		}
+
+		scriptPath := path.Join(KitFunctionsDir, entry.Name())
+		globals, err := l.execScript(kitFS, scriptPath)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(globals))
+		for name := range globals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fn, ok := globals[name].(starlark.Callable)
+			if !ok || strings.HasPrefix(name, "_") {
+				continue
+			}
+			if _, exists := l.reserved[name]; exists {
+				return nil, fmt.Errorf("%s: function '%s' shadows a built-in template function", scriptPath, name)
+			}
+			if _, exists := funcs[name]; exists {
+				return nil, fmt.Errorf("%s: function '%s' is already declared by another script", scriptPath, name)
+			}
+
+			funcs[name] = l.wrap(entry.Name(), name, fn)
+			gl.Log("debug", fmt.Sprintf("Registered kit template function: %s (%s)", name, entry.Name()))
+		}
+	}
+
+	return funcs, nil
+}
+
+func (l *KitFunctionLoader) execScript(kitFS fs.FS, scriptPath string) (starlark.StringDict, error) {
+	src, err := fs.ReadFile(kitFS, scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function script: %w", err)
+	}
+
+	thread := l.newThread(path.Base(scriptPath))
+	timer := time.AfterFunc(l.timeout, func() {
+		thread.Cancel(fmt.Sprintf("script exceeded %s", l.timeout))
+	})
+	defer timer.Stop()
+
+	opts := &syntax.FileOptions{Set: true, While: true, TopLevelControl: true, GlobalReassign: true}
+	globals, err := starlark.ExecFileOptions(opts, thread, scriptPath, src, sandboxPredeclared())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load function script %s: %w", scriptPath, err)
+	}
+
+	// Files render concurrently, so functions can't share mutable state
+	globals.Freeze()
+	return globals, nil
+}
+
+// wrap adapts a Starlark callable to a text/template function
+func (l *KitFunctionLoader) wrap(script, name string, fn starlark.Callable) func(args ...interface{}) (interface{}, error) {
+	return func(args ...interface{}) (interface{}, error) {
+		tuple := make(starlark.Tuple, len(args))
+		for i, arg := range args {
+			value, err := toStarlark(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: argument %d: %w", name, i+1, err)
+			}
+			tuple[i] = value
+		}
+
+		// A fresh thread per call keeps concurrent renders independent
+		thread := l.newThread(script + ":" + name)
+		timer := time.AfterFunc(l.timeout, func() {
+			thread.Cancel(fmt.Sprintf("call exceeded %s", l.timeout))
+		})
+		defer timer.Stop()
+
+		result, err := starlark.Call(thread, fn, tuple, nil)
+		if err != nil {
+			return nil, fmt.Errorf("kit function %s (%s): %w", name, script, err)
+		}
+
+		return fromStarlark(result)
+	}
+}
+
+func (l *KitFunctionLoader) newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			gl.Log("debug", fmt.Sprintf("[%s] %s", name, msg))
+		},
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load(%q) is not allowed in kit functions", module)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxFunctionSteps)
+	return thread
+}
+
+// sandboxPredeclared returns the names available to kit scripts besides the Starlark universe
+func sandboxPredeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"json": starlarkjson.Module,
+	}
+}
+
+func toStarlark(v interface{}) (starlark.Value, error) {
+	switch value := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case string:
+		return starlark.String(value), nil
+	case bool:
+		return starlark.Bool(value), nil
+	case int:
+		return starlark.MakeInt(value), nil
+	case int64:
+		return starlark.MakeInt64(value), nil
+	case float64:
+		return starlark.Float(value), nil
+	case []string:
+		items := make([]starlark.Value, len(value))
+		for i, item := range value {
+			items[i] = starlark.String(item)
+		}
+		return starlark.NewList(items), nil
+	case []interface{}:
+		items := make([]starlark.Value, len(value))
+		for i, item := range value {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+		return starlark.NewList(items), nil
+	case map[string]string:
+		dict := starlark.NewDict(len(value))
+		for key, item := range value {
+			if err := dict.SetKey(starlark.String(key), starlark.String(item)); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(value))
+		for key, item := range value {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key), converted); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	case fmt.Stringer:
+		return starlark.String(value.String()), nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+func fromStarlark(v starlark.Value) (interface{}, error) {
+	switch value := v.(type) {
+	case starlark.NoneType:
+		return "", nil
+	case starlark.String:
+		return string(value), nil
+	case starlark.Bool:
+		return bool(value), nil
+	case starlark.Int:
+		if i, ok := value.Int64(); ok {
+			return i, nil
+		}
+		return value.String(), nil
+	case starlark.Float:
+		f := float64(value)
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return nil, fmt.Errorf("non-finite float result")
+		}
+		return f, nil
+	case *starlark.List:
+		items := make([]interface{}, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, err := fromStarlark(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case starlark.Tuple:
+		items := make([]interface{}, len(value))
+		for i, elem := range value {
+			item, err := fromStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case *starlark.Dict:
+		result := make(map[string]interface{}, value.Len())
+		for _, item := range value.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				key = item[0].String()
+			}
+			converted, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = converted
+		}
+		return result, nil
+	}
+
+	return v.String(), nil
+}
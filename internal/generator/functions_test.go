@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+	"time"
+)
+
+// kitFunctions loads the functions of a kit whose functions directory holds
+// the given scripts
+func kitFunctions(t *testing.T, scripts map[string]string) (template.FuncMap, error) {
+	t.Helper()
+	fsys := fstest.MapFS{}
+	for name, src := range scripts {
+		fsys[KitFunctionsDir+"/"+name] = &fstest.MapFile{Data: []byte(src)}
+	}
+	reserved := template.FuncMap{"upper": strings.ToUpper}
+	return NewKitFunctionLoader(reserved, 200*time.Millisecond).Load(fsys)
+}
+
+func TestKitFunctions(t *testing.T) {
+	funcs, err := kitFunctions(t, map[string]string{
+		"naming.star": `
+def table(name, plural = True):
+    return name.lower() + ("s" if plural else "")
+
+def ports(n):
+    return [8080 + i for i in range(n)]
+
+def config(name):
+    return json.decode('{"name": "%s", "replicas": 2}' % name)
+
+def _helper():
+    return "private"
+`,
+		"notes.txt": "not a script",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := funcs["_helper"]; ok {
+		t.Error("private function _helper was registered")
+	}
+
+	tmpl, err := template.New("t").Funcs(funcs).Parse(
+		`{{table "User"}} {{table "Data" false}} {{range ports 2}}{{.}},{{end}} {{$c := config "api"}}{{index $c "name"}}/{{index $c "replicas"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "users data 8080,8081, api/2"; got != want {
+		t.Errorf("rendered %q, want %q", got, want)
+	}
+}
+
+func TestKitFunctionSandbox(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"shadows a built-in", "def upper(s):\n    return s\n"},
+		{"load", `load("other.star", "x")` + "\n"},
+		{"syntax error", "def broken(:\n"},
+		{"runaway script", "while True:\n    pass\n"},
+	}
+	for _, tt := range tests {
+		if _, err := kitFunctions(t, map[string]string{"f.star": tt.script}); err == nil {
+			t.Errorf("%s: Load succeeded, want an error", tt.name)
+		}
+	}
+
+	if _, err := kitFunctions(t, map[string]string{
+		"a.star": "def same():\n    return 1\n",
+		"b.star": "def same():\n    return 2\n",
+	}); err == nil {
+		t.Error("two scripts declaring the same function were accepted")
+	}
+
+	funcs, err := kitFunctions(t, map[string]string{"loop.star": `
+def spin():
+    while True:
+        pass
+
+def abort():
+    fail("boom")
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"spin", "abort"} {
+		call := funcs[name].(func(...interface{}) (interface{}, error))
+		if _, err := call(); err == nil {
+			t.Errorf("%s() succeeded, want an error", name)
+		}
+	}
+}
@@ -1,33 +1,56 @@
 package generator
 
 import (
-	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
-	"text/template"
 
+	"github.com/rafa-mori/gocrafter/internal/engine"
+	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 )
 
 // Generator handles project generation from templates
 type Generator struct {
-	config        *ProjectConfig
-	templateVars  *TemplateVars
-	templatesPath string
+	config      *ProjectConfig
+	templates   fs.FS // One directory per template
+	postProcess PostProcessOptions
+	jobs        int
+	incrementalState
 }
 
-// NewGenerator creates a new project generator
-func NewGenerator(config *ProjectConfig, templatesPath string) *Generator {
+// NewGenerator creates a new project generator rendering from templates, a
+// filesystem holding one directory per template (embedded, on disk, in
+// memory or inside an archive)
+func NewGenerator(config *ProjectConfig, templates fs.FS) *Generator {
 	return &Generator{
-		config:        config,
-		templateVars:  config.ToTemplateVars(),
-		templatesPath: templatesPath,
+		config:    config,
+		templates: templates,
 	}
 }
 
+// WithPostProcess sets which post-generation steps run
+func (g *Generator) WithPostProcess(opts PostProcessOptions) *Generator {
+	g.postProcess = opts
+	return g
+}
+
+// WithJobs sets how many files are rendered concurrently; 0 uses one worker per CPU
+func (g *Generator) WithJobs(n int) *Generator {
+	g.jobs = n
+	return g
+}
+
+// WithIncremental makes generation update an existing project, see IncrementalOptions
+func (g *Generator) WithIncremental(opts IncrementalOptions) *Generator {
+	g.incremental = opts
+	return g
+}
+
 // Generate creates a new project based on the configuration
 func (g *Generator) Generate() error {
 	gl.Log("Info", fmt.Sprintf("Starting project generation: %s (Template: %s)", g.config.Name, g.config.Template))
@@ -39,171 +62,83 @@ func (g *Generator) Generate() error {
 
 	// Create output directory
 	outputPath := g.config.GetOutputPath()
-	if err := g.createOutputDirectory(outputPath); err != nil {
+	if err := g.createOutputDirectory(outputPath, g.incremental.Enabled); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Get template path
-	templatePath := filepath.Join(g.templatesPath, g.config.Template)
-	if !g.templateExists(templatePath) {
+	// Get template directory
+	templateDir := g.config.Template
+	if !g.templateExists(templateDir) {
 		return fmt.Errorf("template '%s' not found", g.config.Template)
 	}
 
-	// Generate project from template
-	if err := g.generateFromTemplate(templatePath, outputPath); err != nil {
-		return fmt.Errorf("failed to generate project: %w", err)
-	}
-
-	// Post-generation tasks
-	if err := g.runPostGeneration(outputPath); err != nil {
-		gl.Log("warn", fmt.Sprintf("Post-generation tasks failed: %v", err))
-	}
-
-	gl.Log("info", fmt.Sprintf("Project generated successfully: %s", outputPath))
-	return nil
-}
-
-func (g *Generator) createOutputDirectory(outputPath string) error {
-	// Check if directory already exists
-	if _, err := os.Stat(outputPath); err == nil {
-		return fmt.Errorf("directory '%s' already exists", outputPath)
-	}
-
-	// Create directory with all parent directories
-	return os.MkdirAll(outputPath, 0755)
-}
-
-func (g *Generator) templateExists(templatePath string) bool {
-	info, err := os.Stat(templatePath)
-	if err != nil {
-		return false
-	}
-	return info.IsDir()
-}
-
-func (g *Generator) generateFromTemplate(templatePath, outputPath string) error {
-	return filepath.WalkDir(templatePath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Get relative path from template root
-		relPath, err := filepath.Rel(templatePath, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip template root
-		if relPath == "." {
-			return nil
-		}
-
-		// Process path with template variables
-		processedPath := g.processPath(relPath)
-		targetPath := filepath.Join(outputPath, processedPath)
-
-		if d.IsDir() {
-			// Create directory
-			return os.MkdirAll(targetPath, 0755)
-		}
-
-		// Process file
-		return g.processFile(path, targetPath)
-	})
-}
-
-func (g *Generator) processPath(path string) string {
-	// Replace template variables in path
-	processed := strings.ReplaceAll(path, "{{.ProjectName}}", g.templateVars.ProjectName)
-	processed = strings.ReplaceAll(processed, "{{.PackageName}}", g.templateVars.PackageName)
-	processed = strings.ReplaceAll(processed, "{{.ModuleName}}", g.templateVars.ModuleName)
-	return processed
-}
-
-func (g *Generator) processFile(sourcePath, targetPath string) error {
-	// Read source file
-	content, err := os.ReadFile(sourcePath)
+	// Templates are generated like kits, with the project configuration as data
+	info, err := loadTemplateInfo(g.templates, templateDir)
 	if err != nil {
 		return err
 	}
-
-	// Check if file should be processed as template
-	if g.shouldProcessAsTemplate(sourcePath) {
-		processedContent, err := g.processTemplate(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to process template %s: %w", sourcePath, err)
+	src := engine.Source{
+		FS:       g.templates,
+		Dir:      templateDir,
+		Partials: path.Join(templateDir, engine.PartialsDir),
+		Skip:     []string{engine.PartialsDir, templateMetadataFile},
+		Kit:      info.Kit(),
+	}
+	eng := engine.New(g.config.Providers()...)
+	eng.SetJobs(g.jobs)
+	if g.cacheDir == "" {
+		if g.cacheDir, err = defaultCachePath(); err != nil {
+			return err
 		}
-		content = []byte(processedContent)
 	}
-
-	// Write target file
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-		return err
+	if err := g.prepare(eng, outputPath); err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
 	}
-
-	return os.WriteFile(targetPath, content, 0644)
-}
-
-func (g *Generator) shouldProcessAsTemplate(filePath string) bool {
-	// Process certain file types as templates
-	ext := strings.ToLower(filepath.Ext(filePath))
-	templateExtensions := []string{
-		".go", ".mod", ".sum", ".yaml", ".yml", ".json", ".toml",
-		".md", ".txt", ".env", ".dockerfile", ".makefile",
+	if err := eng.Generate(src, outputPath); err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
 	}
-
-	for _, templateExt := range templateExtensions {
-		if ext == templateExt {
-			return true
+	g.finish(eng)
+
+	// Post-generation tasks, skipped when regenerating changed nothing; invalid
+	// generated Go is a template bug and fails generation
+	if g.changed() {
+		if err := g.runPostGeneration(outputPath); err != nil {
+			var bug *TemplateBugError
+			if errors.As(err, &bug) {
+				return bug
+			}
+			gl.Log("warn", fmt.Sprintf("Post-generation tasks failed: %v", err))
 		}
 	}
 
-	// Also check if filename indicates it's a template
-	base := strings.ToLower(filepath.Base(filePath))
-	templateFiles := []string{
-		"makefile", "dockerfile", "readme", "license", "gitignore",
-	}
+	gl.Log("info", fmt.Sprintf("Project generated successfully: %s", outputPath))
+	return nil
+}
 
-	for _, templateFile := range templateFiles {
-		if strings.Contains(base, templateFile) {
-			return true
+func (g *Generator) createOutputDirectory(outputPath string, allowExisting bool) error {
+	// Check if directory already exists
+	if info, err := os.Stat(outputPath); err == nil {
+		if !allowExisting {
+			return fmt.Errorf("directory '%s' already exists", outputPath)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("'%s' is not a directory", outputPath)
 		}
 	}
 
-	return false
+	// Create directory with all parent directories
+	return os.MkdirAll(outputPath, 0755)
 }
 
-func (g *Generator) processTemplate(content string) (string, error) {
-	// Create template with helper functions
-	tmpl := template.New("project").Funcs(template.FuncMap{
-		"lower":    strings.ToLower,
-		"upper":    strings.ToUpper,
-		"title":    strings.Title,
-		"contains": strings.Contains,
-		"hasFeature": func(feature string) bool {
-			for _, f := range g.templateVars.Features {
-				if strings.Contains(strings.ToLower(f), strings.ToLower(feature)) {
-					return true
-				}
-			}
-			return false
-		},
-	})
-
-	// Parse template
-	tmpl, err := tmpl.Parse(content)
-	if err != nil {
-		return "", err
+func (g *Generator) templateExists(templateDir string) bool {
+	if !fs.ValidPath(templateDir) {
+		return false
 	}
-
-	// Execute template
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, g.templateVars)
+	info, err := fs.Stat(g.templates, templateDir)
 	if err != nil {
-		return "", err
+		return false
 	}
-
-	return buf.String(), nil
+	return info.IsDir()
 }
 
 func (g *Generator) runPostGeneration(outputPath string) error {
@@ -214,21 +149,21 @@ func (g *Generator) runPostGeneration(outputPath string) error {
 		return fmt.Errorf("failed to initialize Go module: %w", err)
 	}
 
+	// Format Go code before tidy so import fixes are taken into account
+	if err := g.formatGoCode(outputPath); err != nil {
+		return fmt.Errorf("failed to format Go code: %w", err)
+	}
+
 	// Run go mod tidy
 	if err := g.runGoModTidy(outputPath); err != nil {
 		return fmt.Errorf("failed to run go mod tidy: %w", err)
 	}
 
-	// Format Go code
-	if err := g.formatGoCode(outputPath); err != nil {
-		return fmt.Errorf("failed to format Go code: %w", err)
-	}
-
 	return nil
 }
 
 func (g *Generator) initGoModule(outputPath string) error {
-	if g.config.Module == "" {
+	if g.config.Module == "" || g.postProcess.SkipModInit {
 		return nil
 	}
 
@@ -240,70 +175,94 @@ func (g *Generator) initGoModule(outputPath string) error {
 	}
 
 	gl.Log("info", fmt.Sprintf("Initializing Go module: %s", g.config.Module))
-	return nil // go mod init will be handled by the template
+	err := runGo(outputPath, g.postProcess.Offline, "mod", "init", g.config.Module)
+	if err == errGoNotFound {
+		gl.Log("warn", "Go toolchain not found, skipping go mod init")
+		return nil
+	}
+	return err
 }
 
 func (g *Generator) runGoModTidy(outputPath string) error {
+	if g.postProcess.SkipTidy {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "go.mod")); err != nil {
+		return nil
+	}
+
 	gl.Log("info", fmt.Sprintf("Running go mod tidy: %s", outputPath))
-	// This would execute: go mod tidy in the output directory
-	// For now, we'll leave this as a placeholder since we want to generate templates first
-	return nil
+	err := runGo(outputPath, g.postProcess.Offline, "mod", "tidy")
+	if err == errGoNotFound {
+		gl.Log("warn", "Go toolchain not found, skipping go mod tidy")
+		return nil
+	}
+	return err
 }
 
 func (g *Generator) formatGoCode(outputPath string) error {
+	if g.postProcess.SkipFormat {
+		return nil
+	}
+
 	gl.Log("info", fmt.Sprintf("Formatting Go code: %s", outputPath))
-	// This would execute: gofmt -w . in the output directory
-	// For now, we'll leave this as a placeholder
-	return nil
+	return formatGoFiles(outputPath)
 }
 
-// GetTemplateInfo returns information about a template
-func GetTemplateInfo(templatesPath, templateName string) (*TemplateInfo, error) {
-	templatePath := filepath.Join(templatesPath, templateName)
-
-	if !templateExists(templatePath) {
-		return nil, fmt.Errorf("template '%s' not found", templateName)
-	}
-
-	// Read template metadata if it exists
-	metadataPath := filepath.Join(templatePath, "template.json")
-	if _, err := os.Stat(metadataPath); err == nil {
-		// Template has metadata file
-		return readTemplateMetadata(metadataPath)
+// GetTemplateInfo returns information about a template in templates
+func GetTemplateInfo(templates fs.FS, templateName string) (*TemplateInfo, error) {
+	tmpl, err := DiscoverTemplates([]TemplateRoot{{Name: ".", FS: templates}}).Get(templateName)
+	if err != nil {
+		return nil, err
 	}
-
-	// Default template info
-	return &TemplateInfo{
-		Name:        templateName,
-		Description: fmt.Sprintf("Template for %s projects", templateName),
-		Version:     "1.0.0",
-	}, nil
+	return tmpl.Info, nil
 }
 
+// templateMetadataFile describes a built-in template and is never emitted
+const templateMetadataFile = "template.json"
+
 // TemplateInfo contains information about a template
 type TemplateInfo struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Version     string   `json:"version"`
-	Author      string   `json:"author,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-	Features    []string `json:"features,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Version     string            `json:"version"`
+	Author      string            `json:"author,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Features    []string          `json:"features,omitempty"`
+	Conditions  map[string]string `json:"conditions,omitempty"`
 }
 
-func templateExists(templatePath string) bool {
-	info, err := os.Stat(templatePath)
-	if err != nil {
-		return false
+// Kit describes the template as a kit, so it is generated by the same engine
+func (t *TemplateInfo) Kit() *types.Kit {
+	return &types.Kit{
+		Name:        t.Name,
+		Description: t.Description,
+		Version:     t.Version,
+		Author:      t.Author,
+		Tags:        t.Tags,
+		Conditions:  t.Conditions,
 	}
-	return info.IsDir()
 }
 
-func readTemplateMetadata(metadataPath string) (*TemplateInfo, error) {
-	// This would read and parse the template.json file
-	// For now, return a default
-	return &TemplateInfo{
-		Name:        "unknown",
-		Description: "Template",
-		Version:     "1.0.0",
-	}, nil
+// loadTemplateInfo reads the template's metadata file; a missing file yields
+// empty metadata
+func loadTemplateInfo(templates fs.FS, templateDir string) (*TemplateInfo, error) {
+	metadataPath := path.Join(templateDir, templateMetadataFile)
+	if _, err := fs.Stat(templates, metadataPath); errors.Is(err, fs.ErrNotExist) {
+		return &TemplateInfo{}, nil
+	}
+	return readTemplateMetadata(templates, metadataPath)
+}
+
+func readTemplateMetadata(templates fs.FS, metadataPath string) (*TemplateInfo, error) {
+	data, err := fs.ReadFile(templates, metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template metadata: %w", err)
+	}
+
+	var info TemplateInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", templateMetadataFile, err)
+	}
+	return &info, nil
 }
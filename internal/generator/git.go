@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/engine"
+)
+
+// KitHooksDir holds git hooks a kit installs into generated repositories
+const KitHooksDir = "hooks"
+
+// DefaultGitBranch is used when neither flags, kit nor profile set a branch
+const DefaultGitBranch = "main"
+
+// GitOptions configures the repository created for a generated project
+type GitOptions struct {
+	Branch      string // Default branch name
+	Remote      string // Optional URL added as "origin"
+	AuthorName  string // Commit author; git configuration is used when empty
+	AuthorEmail string
+	HooksDir    string // Directory whose files are installed as git hooks
+	Source      string // What the project was generated from, e.g. "kit golang-api 1.2.0"
+}
+
+// InitGitRepository initializes a repository in projectPath, installs hooks,
+// commits every generated file as "Initial scaffold" and adds the remote
+func InitGitRepository(projectPath string, opts GitOptions) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found in PATH")
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = DefaultGitBranch
+	}
+
+	// Setting HEAD explicitly works on git versions without --initial-branch
+	if err := runGit(projectPath, "init", "--quiet"); err != nil {
+		return err
+	}
+	if err := runGit(projectPath, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+		return err
+	}
+
+	if opts.HooksDir != "" {
+		if err := installGitHooks(projectPath, opts.HooksDir); err != nil {
+			return fmt.Errorf("failed to install git hooks: %w", err)
+		}
+	}
+
+	if err := runGit(projectPath, "add", "--all"); err != nil {
+		return err
+	}
+
+	message := "Initial scaffold"
+	if opts.Source != "" {
+		message += fmt.Sprintf("\n\nGenerated by gocrafter from %s.", opts.Source)
+	}
+
+	var args []string
+	if opts.AuthorName != "" {
+		args = append(args, "-c", "user.name="+opts.AuthorName)
+	}
+	if opts.AuthorEmail != "" {
+		args = append(args, "-c", "user.email="+opts.AuthorEmail)
+	}
+	// Hooks installed by the kit must not block the scaffold commit
+	args = append(args, "commit", "--quiet", "--no-verify", "--allow-empty", "-m", message)
+	if err := runGit(projectPath, args...); err != nil {
+		return err
+	}
+
+	if opts.Remote != "" {
+		if err := runGit(projectPath, "remote", "add", "origin", opts.Remote); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// installGitHooks copies every file in hooksDir to .git/hooks as an executable
+func installGitHooks(projectPath, hooksDir string) error {
+	entries, err := os.ReadDir(hooksDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(projectPath, ".git", "hooks")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(hooksDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := engine.WriteFileMode(filepath.Join(target, entry.Name()), content, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// repoGit runs git in the repository at dir and returns its output
+func repoGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := gitOutput(append([]string{"-C", dir}, args...)...)
+	if err != nil {
+		t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+func TestInitGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	// Keep the user's git configuration (signing, templates) out of the test
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	writeKit(t, dir, map[string]string{
+		"project/main.go": "package main\n",
+		// A failing hook must not block the scaffold commit
+		"hooks/pre-commit": "#!/bin/sh\nexit 1\n",
+		"hooks/commit-msg": "#!/bin/sh\nexit 0\n",
+	})
+
+	err := InitGitRepository(project, GitOptions{
+		Branch:      "trunk",
+		Remote:      "git@example.com:acme/project.git",
+		AuthorName:  "Ana Lima",
+		AuthorEmail: "ana@example.com",
+		HooksDir:    filepath.Join(dir, "hooks"),
+		Source:      "kit golang-api 1.2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if branch := repoGit(t, project, "symbolic-ref", "--short", "HEAD"); branch != "trunk" {
+		t.Errorf("branch = %q, want trunk", branch)
+	}
+	if author := repoGit(t, project, "log", "-1", "--format=%an <%ae>"); author != "Ana Lima <ana@example.com>" {
+		t.Errorf("author = %q", author)
+	}
+	if remote := repoGit(t, project, "remote", "get-url", "origin"); remote != "git@example.com:acme/project.git" {
+		t.Errorf("origin = %q", remote)
+	}
+	message := repoGit(t, project, "log", "-1", "--format=%B")
+	if want := "Initial scaffold\n\nGenerated by gocrafter from kit golang-api 1.2.0."; message != want {
+		t.Errorf("commit message = %q, want %q", message, want)
+	}
+	if files := repoGit(t, project, "ls-files"); files != "main.go" {
+		t.Errorf("committed files = %q, want main.go", files)
+	}
+
+	for _, hook := range []string{"pre-commit", "commit-msg"} {
+		info, err := os.Stat(filepath.Join(project, ".git", "hooks", hook))
+		if err != nil {
+			t.Errorf("hook %s not installed: %v", hook, err)
+			continue
+		}
+		if info.Mode().Perm()&0111 == 0 {
+			t.Errorf("hook %s is not executable", hook)
+		}
+	}
+}
+
+func TestInitGitRepositoryDefaults(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Env Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "env@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Env Author")
+	t.Setenv("GIT_COMMITTER_EMAIL", "env@example.com")
+
+	project := t.TempDir()
+	if err := InitGitRepository(project, GitOptions{HooksDir: filepath.Join(project, "missing")}); err != nil {
+		t.Fatal(err)
+	}
+	if branch := repoGit(t, project, "symbolic-ref", "--short", "HEAD"); branch != DefaultGitBranch {
+		t.Errorf("branch = %q, want %s", branch, DefaultGitBranch)
+	}
+	if message := repoGit(t, project, "log", "-1", "--format=%B"); message != "Initial scaffold" {
+		t.Errorf("commit message = %q", message)
+	}
+	if remotes := repoGit(t, project, "remote"); remotes != "" {
+		t.Errorf("remotes = %q, want none", remotes)
+	}
+}
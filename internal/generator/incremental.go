@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+
+	gcconfig "github.com/rafa-mori/gocrafter/internal/config"
+	"github.com/rafa-mori/gocrafter/internal/engine"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// manifestsDir is the cache directory holding one manifest per generated project
+const manifestsDir = "regen"
+
+// IncrementalOptions makes generation update an existing project, skipping
+// files whose inputs and contents are unchanged, instead of requiring a new
+// directory
+type IncrementalOptions struct {
+	Enabled bool
+	Prune   bool // Delete files that are no longer produced instead of only reporting them
+	Force   bool // Overwrite files edited since they were generated instead of keeping them
+}
+
+// ManifestPath returns where the manifest of the project generated into
+// outputPath is kept. Manifests live in cacheDir, so projects stay clean.
+func ManifestPath(cacheDir, outputPath string) (string, error) {
+	abs, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(cacheDir, manifestsDir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// defaultCachePath is the configured cache directory, used when no kit
+// manager provides one
+func defaultCachePath() (string, error) {
+	config, err := gcconfig.Load()
+	if err != nil {
+		return "", err
+	}
+	return config.CachePath, nil
+}
+
+// incrementalState is the incremental generation state shared by the template
+// and kit generators
+type incrementalState struct {
+	incremental  IncrementalOptions
+	cacheDir     string
+	outputPath   string
+	manifest     *engine.Manifest
+	report       *engine.IncrementalReport
+	regenerating bool // The previous manifest listed files, so the project existed
+}
+
+// loadManifest returns the previous manifest of the project at outputPath
+func (s *incrementalState) loadManifest(outputPath string) (*engine.Manifest, error) {
+	manifestPath, err := ManifestPath(s.cacheDir, outputPath)
+	if err != nil {
+		return nil, err
+	}
+	return engine.LoadManifest(manifestPath)
+}
+
+// PreviousAnswers returns the values the project at outputPath was last
+// generated with, if it was generated incrementally
+func (s *incrementalState) PreviousAnswers(outputPath string) (map[string]string, error) {
+	prev, err := s.loadManifest(outputPath)
+	if err != nil {
+		return nil, err
+	}
+	return prev.Answers, nil
+}
+
+// prepare sets up eng to record a manifest of outputPath. New projects start
+// from an empty one, so a later incremental run knows what they contain.
+func (s *incrementalState) prepare(eng *engine.Engine, outputPath string) error {
+	s.outputPath = outputPath
+	s.manifest, s.report, s.regenerating = nil, nil, false
+	if !s.incremental.Enabled {
+		eng.SetIncremental(engine.NewManifest(), false, false)
+		return nil
+	}
+
+	prev, err := s.loadManifest(outputPath)
+	if err != nil {
+		return err
+	}
+	s.regenerating = len(prev.Files) > 0
+	eng.SetIncremental(prev, s.incremental.Prune, s.incremental.Force)
+	return nil
+}
+
+// finish records the outcome of eng's generation
+func (s *incrementalState) finish(eng *engine.Engine) {
+	s.manifest = eng.Manifest()
+	if !s.incremental.Enabled {
+		return
+	}
+	s.report = eng.IncrementalReport()
+	if s.report == nil {
+		return
+	}
+	gl.Log("info", fmt.Sprintf("Incremental generation: %d written, %d unchanged, %d edited, %d stale, %d removed",
+		len(s.report.Written), len(s.report.Unchanged), len(s.report.Edited), len(s.report.Stale), len(s.report.Removed)))
+	for _, name := range s.report.Edited {
+		gl.Log("warn", fmt.Sprintf("Keeping %s: modified since it was generated (use --force to overwrite)", name))
+	}
+	for _, name := range s.report.Stale {
+		if !containsString(s.report.Removed, name) {
+			gl.Log("warn", fmt.Sprintf("No longer generated: %s", name))
+		}
+	}
+}
+
+// changed reports whether the last generation wrote or removed anything;
+// post-processing is skipped for incremental runs that didn't
+func (s *incrementalState) changed() bool {
+	return s.report == nil || len(s.report.Written) > 0 || len(s.report.Removed) > 0
+}
+
+// IncrementalReport returns what the last incremental generation did, or nil
+func (s *incrementalState) IncrementalReport() *engine.IncrementalReport {
+	return s.report
+}
+
+// SaveManifest stores the manifest of the last generation for the next
+// incremental run. Call it once every step that modifies generated files (license
+// headers, formatters) is done, so their changes are not mistaken for edits.
+func (s *incrementalState) SaveManifest() error {
+	if s.manifest == nil {
+		return nil
+	}
+	if err := s.manifest.Refresh(s.outputPath); err != nil {
+		return fmt.Errorf("failed to hash generated files: %w", err)
+	}
+	manifestPath, err := ManifestPath(s.cacheDir, s.outputPath)
+	if err != nil {
+		return err
+	}
+	return s.manifest.Save(manifestPath)
+}
+
+// kitFunctionsFingerprint hashes a kit's function scripts, which affect
+// rendering without being part of its templates
+func kitFunctionsFingerprint(kitFS fs.FS) string {
+	entries, err := fs.ReadDir(kitFS, KitFunctionsDir)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".star" {
+			continue
+		}
+		content, err := fs.ReadFile(kitFS, path.Join(KitFunctionsDir, entry.Name()))
+		if err != nil {
+			return ""
+		}
+		fmt.Fprintf(h, "%s %d\n%s\n", entry.Name(), len(content), content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -1,31 +1,69 @@
 package generator
 
 import (
+	"bufio"
 	"fmt"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/rafa-mori/gocrafter/internal/engine"
 	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 )
 
 // KitGenerator generates projects from kits
 type KitGenerator struct {
-	kitManager *KitManagerImpl
-	replacer   *PlaceholderReplacer
+	kitManager  *KitManagerImpl
+	postProcess PostProcessOptions
+	jobs        int
+	strict      bool
+	results     []PostProcessResult
+	incrementalState
 }
 
 // NewKitGenerator creates a new kit-based project generator
 func NewKitGenerator(kitManager *KitManagerImpl) *KitGenerator {
 	return &KitGenerator{
-		kitManager: kitManager,
-		replacer:   NewPlaceholderReplacer(),
+		kitManager:       kitManager,
+		incrementalState: incrementalState{cacheDir: kitManager.config.CachePath},
 	}
 }
 
+// WithPostProcess sets options for the post-processors run after generation
+func (kg *KitGenerator) WithPostProcess(opts PostProcessOptions) *KitGenerator {
+	kg.postProcess = opts
+	return kg
+}
+
+// WithJobs sets how many files are rendered concurrently; 0 uses one worker per CPU
+func (kg *KitGenerator) WithJobs(n int) *KitGenerator {
+	kg.jobs = n
+	return kg
+}
+
+// WithIncremental makes generation update an existing project, see IncrementalOptions
+func (kg *KitGenerator) WithIncremental(opts IncrementalOptions) *KitGenerator {
+	kg.incremental = opts
+	return kg
+}
+
+// WithStrict makes template files that fail to parse or execute fail
+// generation with an *engine.TemplateError, instead of being kept with only
+// the {{name}} shorthand applied
+func (kg *KitGenerator) WithStrict(strict bool) *KitGenerator {
+	kg.strict = strict
+	return kg
+}
+
+// PostProcessResults returns the post-processor outcomes of the last generation
+func (kg *KitGenerator) PostProcessResults() []PostProcessResult {
+	return kg.results
+}
+
 // GenerateFromKit generates a project from a kit
 func (kg *KitGenerator) GenerateFromKit(req *types.GenerationRequest) error {
 	gl.Log("info", fmt.Sprintf("Generating project '%s' from kit '%s'", req.ProjectName, req.KitName))
@@ -36,28 +74,75 @@ func (kg *KitGenerator) GenerateFromKit(req *types.GenerationRequest) error {
 		return fmt.Errorf("failed to get kit: %w", err)
 	}
 
-	// Validate output path
-	if err := kg.validateOutputPath(req.OutputPath); err != nil {
+	return kg.GenerateFromFS(kit, engine.DirFS(kit.LocalPath), req)
+}
+
+// GenerateFromFS generates a project from a kit whose files (templates/,
+// partials/, functions/) are read from kitFS, which may be on disk, embedded,
+// in memory or inside an archive. scaffold.sh only runs for kits on disk.
+func (kg *KitGenerator) GenerateFromFS(kit *types.Kit, kitFS fs.FS, req *types.GenerationRequest) error {
+	// Validate output path; incremental generation updates existing projects
+	if err := kg.validateOutputPath(req.OutputPath, kg.incremental.Enabled); err != nil {
 		return fmt.Errorf("invalid output path: %w", err)
 	}
 
-	// Setup placeholders
-	kg.replacer.SetPlaceholdersFromRequest(req)
-	
-	// Add kit-specific placeholders
-	if err := kg.setupKitPlaceholders(kit, req); err != nil {
-		return fmt.Errorf("failed to setup kit placeholders: %w", err)
+	// Setup placeholders; kits also get TemplateVars-style data and hasFeature
+	placeholders := kitPlaceholders(kit, req)
+	eng := engine.New(
+		ProjectProvider(projectConfigFromPlaceholders(req.ProjectName, placeholders)),
+		placeholders,
+	)
+	eng.SetJobs(kg.jobs)
+
+	// Register kit-declared template functions
+	if err := kg.setupKitFunctions(eng, kit, kitFS); err != nil {
+		return fmt.Errorf("failed to load kit functions: %w", err)
 	}
 
 	// Generate project structure
-	templatesPath := filepath.Join(kit.LocalPath, "templates")
-	if err := kg.generateFromTemplates(templatesPath, req.OutputPath); err != nil {
+	src := engine.Source{
+		FS:       kitFS,
+		Dir:      engine.KitTemplatesDir,
+		Partials: engine.PartialsDir,
+		Kit:      kit,
+		Lenient:  !kg.strict,
+	}
+	if kg.incremental.Enabled {
+		// Function scripts affect every file without being templates themselves
+		src.Fingerprint = kitFunctionsFingerprint(kitFS)
+	}
+	if err := kg.prepare(eng, req.OutputPath); err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if err := eng.Generate(src, req.OutputPath); err != nil {
 		return fmt.Errorf("failed to generate from templates: %w", err)
 	}
+	kg.finish(eng)
 
-	// Run post-generation script if exists
-	if err := kg.runPostGenerationScript(kit.LocalPath, req.OutputPath); err != nil {
-		gl.Log("warn", fmt.Sprintf("Post-generation script failed: %v", err))
+	// Remember the answers, so regenerating doesn't prompt again
+	if kg.manifest != nil {
+		kg.manifest.Answers = make(map[string]string, len(req.Placeholders))
+		for _, p := range req.Placeholders {
+			kg.manifest.Answers[p.Name] = p.Value
+		}
+	}
+
+	// Post-processing is skipped when regenerating changed nothing
+	kg.results = nil
+	if kg.changed() {
+		// Run post-generation script if exists; it only scaffolds new projects
+		if kit.LocalPath != "" && !kg.regenerating {
+			if err := kg.runPostGenerationScript(kit.LocalPath, req.OutputPath); err != nil {
+				gl.Log("warn", fmt.Sprintf("Post-generation script failed: %v", err))
+			}
+		}
+
+		// Run language post-processors
+		processors, err := selectPostProcessors(kit.Language, kit.PostProcess)
+		if err != nil {
+			return fmt.Errorf("invalid kit post_process: %w", err)
+		}
+		kg.results = runPostProcessors(processors, req.OutputPath, kg.postProcess)
 	}
 
 	gl.Log("info", fmt.Sprintf("Project '%s' generated successfully at: %s", req.ProjectName, req.OutputPath))
@@ -92,22 +177,57 @@ func (kg *KitGenerator) GetKitPlaceholders(kitName string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get kit: %w", err)
 	}
+	return kg.KitPlaceholders(kit)
+}
 
+// KitPlaceholders returns all placeholders required by a kit on disk
+func (kg *KitGenerator) KitPlaceholders(kit *types.Kit) ([]string, error) {
 	// Get placeholders from kit metadata
 	placeholders := make([]string, len(kit.Placeholders))
 	copy(placeholders, kit.Placeholders)
 
 	// Extract additional placeholders from templates
-	templatePlaceholders, err := ExtractPlaceholdersFromKit(kit.LocalPath)
+	kitFS := engine.DirFS(kit.LocalPath)
+	templatePlaceholders, err := engine.ExtractPlaceholders(kit, kitFS)
 	if err != nil {
 		gl.Log("warn", fmt.Sprintf("Failed to extract placeholders from templates: %v", err))
 	} else {
-		// Merge with metadata placeholders
+		// Merge with metadata placeholders, skipping template function names
 		seen := make(map[string]bool)
 		for _, p := range placeholders {
 			seen[p] = true
 		}
-		
+		funcs := engine.New(ProjectProvider(NewProjectConfig())).Funcs()
+		for name := range funcs {
+			seen[name] = true
+		}
+		kitFuncs, err := NewKitFunctionLoader(funcs, 0).Load(kitFS)
+		if err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to load kit functions: %v", err))
+		}
+		for name := range kitFuncs {
+			seen[name] = true
+		}
+		for _, keyword := range templateKeywords {
+			seen[keyword] = true
+		}
+		for _, builtin := range builtinPlaceholders {
+			seen[builtin] = true
+		}
+		for name := range ProjectProvider(NewProjectConfig()).Values() {
+			seen[name] = true
+		}
+
+		// Values referenced only by file conditions or foreach rules still need answers
+		if rules, err := engine.NewFileRules(kit.Conditions); err == nil {
+			templatePlaceholders = append(templatePlaceholders, rules.Identifiers()...)
+		}
+		if len(kit.Foreach) > 0 {
+			seen[engine.LoopItemPlaceholder] = true
+			seen[engine.LoopIndexPlaceholder] = true
+			templatePlaceholders = append(templatePlaceholders, engine.ForeachLists(kit)...)
+		}
+
 		for _, p := range templatePlaceholders {
 			if !seen[p] {
 				placeholders = append(placeholders, p)
@@ -121,10 +241,15 @@ func (kg *KitGenerator) GetKitPlaceholders(kitName string) ([]string, error) {
 
 // Private methods
 
-func (kg *KitGenerator) validateOutputPath(outputPath string) error {
+func (kg *KitGenerator) validateOutputPath(outputPath string, allowExisting bool) error {
 	// Check if path already exists
-	if _, err := os.Stat(outputPath); err == nil {
-		return fmt.Errorf("output path '%s' already exists", outputPath)
+	if info, err := os.Stat(outputPath); err == nil {
+		if !allowExisting {
+			return fmt.Errorf("output path '%s' already exists", outputPath)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("output path '%s' is not a directory", outputPath)
+		}
 	}
 
 	// Check if parent directory exists and is writable
@@ -145,140 +270,83 @@ func (kg *KitGenerator) validateOutputPath(outputPath string) error {
 	return nil
 }
 
-func (kg *KitGenerator) setupKitPlaceholders(kit *types.Kit, req *types.GenerationRequest) error {
-	// Set kit metadata as placeholders
-	kg.replacer.SetPlaceholder("kit_name", kit.Name)
-	kg.replacer.SetPlaceholder("kit_version", kit.Version)
-	kg.replacer.SetPlaceholder("kit_author", kit.Author)
-	
-	// Set default values for common placeholders if not provided
-	kg.setDefaultPlaceholders(req)
+// builtinPlaceholders are set by the generator itself and never need prompting
+var builtinPlaceholders = []string{
+	"project_name", "current_year", "package_name", "module_name", "class_name", "const_name",
+	"kit_name", "kit_version", "kit_author",
+}
 
-	return nil
+// templateKeywords are text/template actions that look like placeholders
+var templateKeywords = []string{
+	"if", "else", "end", "range", "with", "template", "define", "block", "break", "continue", "nil",
 }
 
-func (kg *KitGenerator) setDefaultPlaceholders(req *types.GenerationRequest) {
-	defaults := map[string]string{
-		"author":      "Developer",
-		"license":     "MIT",
-		"description": fmt.Sprintf("A project generated from kit %s", req.KitName),
-		"version":     "1.0.0",
+// kitPlaceholders collects the values a kit renders with: request answers,
+// values derived from the project name, kit metadata and common defaults
+func kitPlaceholders(kit *types.Kit, req *types.GenerationRequest) engine.Placeholders {
+	base := basePlaceholders(req.ProjectName)
+	placeholders := engine.Placeholders{
+		"project_name": base["project_name"],
+		"current_year": base["current_year"],
 	}
 
-	// Only set defaults if not already provided
+	// Set placeholders from request
 	provided := make(map[string]bool)
 	for _, p := range req.Placeholders {
+		placeholders[p.Name] = p.Value
 		provided[p.Name] = true
 	}
 
-	for name, value := range defaults {
-		if !provided[name] {
-			kg.replacer.SetPlaceholder(name, value)
+	// Set derived placeholders
+	for name, value := range base {
+		if name != "project_name" && name != "current_year" {
+			placeholders[name] = value
 		}
 	}
-}
 
-func (kg *KitGenerator) generateFromTemplates(templatesPath, outputPath string) error {
-	return filepath.WalkDir(templatesPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Get relative path from templates root
-		relPath, err := filepath.Rel(templatesPath, path)
-		if err != nil {
-			return err
-		}
-
-		// Skip templates root
-		if relPath == "." {
-			return nil
-		}
-
-		// Process path with placeholders
-		processedPath := kg.replacer.ProcessPath(relPath)
-		targetPath := filepath.Join(outputPath, processedPath)
-
-		if d.IsDir() {
-			// Create directory
-			return os.MkdirAll(targetPath, 0755)
-		}
-
-		// Process file
-		return kg.processTemplateFile(path, targetPath)
-	})
-}
+	// Set kit metadata as placeholders
+	placeholders["kit_name"] = kit.Name
+	placeholders["kit_version"] = kit.Version
+	placeholders["kit_author"] = kit.Author
 
-func (kg *KitGenerator) processTemplateFile(sourcePath, targetPath string) error {
-	// Read source file
-	content, err := os.ReadFile(sourcePath)
-	if err != nil {
-		return fmt.Errorf("failed to read source file: %w", err)
+	// Set default values for common placeholders if not provided
+	defaults := map[string]string{
+		"author":      "Developer",
+		"license":     "MIT",
+		"description": fmt.Sprintf("A project generated from kit %s", req.KitName),
+		"version":     "1.0.0",
 	}
-
-	// Process content with placeholders if it's a template file
-	processedContent := string(content)
-	if kg.shouldProcessAsTemplate(sourcePath) {
-		processedContent, err = kg.replacer.ProcessContent(string(content))
-		if err != nil {
-			return fmt.Errorf("failed to process template content: %w", err)
+	for name, value := range defaults {
+		if !provided[name] {
+			placeholders[name] = value
 		}
 	}
-
-	// Ensure target directory exists
-	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-		return fmt.Errorf("failed to create target directory: %w", err)
-	}
-
-	// Write processed content to target file
-	if err := os.WriteFile(targetPath, []byte(processedContent), 0644); err != nil {
-		return fmt.Errorf("failed to write target file: %w", err)
-	}
-
-	gl.Log("debug", fmt.Sprintf("Generated file: %s", targetPath))
-	return nil
+	return placeholders
 }
 
-func (kg *KitGenerator) shouldProcessAsTemplate(filePath string) bool {
-	// Check file extension
-	ext := strings.ToLower(filepath.Ext(filePath))
-	templateExtensions := []string{
-		".go", ".mod", ".sum", ".yaml", ".yml", ".json", ".toml",
-		".md", ".txt", ".env", ".dockerfile", ".makefile", ".sh",
-		".js", ".ts", ".jsx", ".tsx", ".css", ".scss", ".html",
-		".py", ".rs", ".java", ".kt", ".cpp", ".c", ".h",
-	}
-
-	for _, templateExt := range templateExtensions {
-		if ext == templateExt {
-			return true
-		}
-	}
-
-	// Check filename patterns
-	base := strings.ToLower(filepath.Base(filePath))
-	templateFiles := []string{
-		"makefile", "dockerfile", "readme", "license", "gitignore",
-		"changelog", "contributing", "notice", "authors",
-	}
-
-	for _, templateFile := range templateFiles {
-		if strings.Contains(base, templateFile) {
-			return true
+func (kg *KitGenerator) setupKitFunctions(eng *engine.Engine, kit *types.Kit, kitFS fs.FS) error {
+	timeout := DefaultFunctionTimeout
+	if value := kit.Metadata["function_timeout"]; value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid function_timeout '%s': %w", value, err)
 		}
+		timeout = parsed
 	}
 
-	// Check for .tpl extension
-	if strings.HasSuffix(filePath, ".tpl") {
-		return true
+	loader := NewKitFunctionLoader(eng.Funcs(), timeout)
+	funcs, err := loader.Load(kitFS)
+	if err != nil {
+		return err
 	}
 
-	return false
+	eng.AddFuncs(funcs)
+	return nil
 }
 
 func (kg *KitGenerator) runPostGenerationScript(kitPath, outputPath string) error {
 	scriptPath := filepath.Join(kitPath, "scaffold.sh")
-	
+
 	// Check if script exists
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 		return nil // No script to run
@@ -286,13 +354,10 @@ func (kg *KitGenerator) runPostGenerationScript(kitPath, outputPath string) erro
 
 	gl.Log("info", "Running post-generation script...")
 
-	// Make script executable
-	if err := os.Chmod(scriptPath, 0755); err != nil {
-		return fmt.Errorf("failed to make script executable: %w", err)
-	}
-
-	// Run script in the output directory
-	cmd := exec.Command("/bin/bash", scriptPath)
+	// The script is passed to its interpreter rather than made executable:
+	// the kit directory may be a linked kit, the user's own source tree
+	name, args := scriptInterpreter(scriptPath)
+	cmd := exec.Command(name, append(args, scriptPath)...)
 	cmd.Dir = outputPath
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -310,3 +375,23 @@ func (kg *KitGenerator) runPostGenerationScript(kitPath, outputPath string) erro
 	gl.Log("info", "Post-generation script completed successfully")
 	return nil
 }
+
+// scriptInterpreter returns the interpreter named by a script's #! line, such
+// as bash for "#!/usr/bin/env bash", or sh when it has none
+func scriptInterpreter(scriptPath string) (string, []string) {
+	file, err := os.Open(scriptPath)
+	if err != nil {
+		return "sh", nil
+	}
+	defer file.Close()
+
+	line, _ := bufio.NewReader(file).ReadString('\n')
+	if !strings.HasPrefix(line, "#!") {
+		return "sh", nil
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return "sh", nil
+	}
+	return fields[0], fields[1:]
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// kitLinksFile is the registry of linked kits, kept in the kits directory
+const kitLinksFile = "links.yaml"
+
+// kitLinks maps linked kit names to the directories they are read from
+type kitLinks struct {
+	Links map[string]string `yaml:"links"`
+}
+
+// loadLinks reads the linked kit registry; a missing registry is empty
+func (km *KitManagerImpl) loadLinks() (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(km.kitsPath, kitLinksFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read linked kits: %w", err)
+	}
+
+	var registry kitLinks
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", kitLinksFile, err)
+	}
+	if registry.Links == nil {
+		registry.Links = map[string]string{}
+	}
+	return registry.Links, nil
+}
+
+// saveLinks writes the linked kit registry
+func (km *KitManagerImpl) saveLinks(links map[string]string) error {
+	data, err := yaml.Marshal(kitLinks{Links: links})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(km.kitsPath, kitLinksFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write linked kits: %w", err)
+	}
+	return nil
+}
+
+// LinkKit registers the kit in kitPath by reference, so edits to it are
+// picked up without reinstalling. It returns the kit name.
+func (km *KitManagerImpl) LinkKit(kitPath string) (string, error) {
+	kit, err := km.LoadKit(kitPath)
+	if err != nil {
+		return "", fmt.Errorf("kit validation failed: %w", err)
+	}
+	name := LinkName(kit)
+
+	links, err := km.loadLinks()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := links[name]; ok {
+		return "", fmt.Errorf("kit '%s' already exists", name)
+	}
+	if _, err := os.Stat(filepath.Join(km.kitsPath, name)); err == nil {
+		return "", fmt.Errorf("kit '%s' already exists", name)
+	}
+
+	links[name] = kit.LocalPath
+	if err := km.saveLinks(links); err != nil {
+		return "", err
+	}
+
+	gl.Log("info", fmt.Sprintf("Kit '%s' linked to %s", name, kit.LocalPath))
+	return name, nil
+}
+
+// LinkName is the name a kit is linked under: its metadata name, or its
+// directory name if it has none
+func LinkName(kit *types.Kit) string {
+	if kit.Name != "" {
+		return kit.Name
+	}
+	return filepath.Base(kit.LocalPath)
+}
+
+// LinkedPath returns the directory a linked kit is read from
+func (km *KitManagerImpl) LinkedPath(name string) (string, bool) {
+	links, err := km.loadLinks()
+	if err != nil {
+		return "", false
+	}
+	path, ok := links[name]
+	return path, ok
+}
+
+// unlinkKit removes a kit from the registry; its directory is left alone
+func (km *KitManagerImpl) unlinkKit(name string) error {
+	links, err := km.loadLinks()
+	if err != nil {
+		return err
+	}
+	delete(links, name)
+	return km.saveLinks(links)
+}
+
+// loadLinkedKit loads a linked kit. A kit whose directory is gone or invalid
+// is returned marked as broken, together with the reason.
+func (km *KitManagerImpl) loadLinkedKit(name, kitPath string) (*types.Kit, error) {
+	kit, err := km.LoadKit(kitPath)
+	if err != nil {
+		return &types.Kit{Name: name, LocalPath: kitPath, Linked: true, Broken: err.Error()}, err
+	}
+	kit.Linked = true
+	return kit, nil
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+func TestLinkedKits(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv(KitsPathEnv, "")
+	km, err := NewKitManager(&types.KitConfig{
+		KitsPath:  filepath.Join(dir, "kits"),
+		CachePath: filepath.Join(dir, "cache"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The kit is linked under its metadata name, not its directory name
+	kitPath := filepath.Join(dir, "src", "my-kit")
+	writeKit(t, kitPath, map[string]string{
+		"metadata.yaml":       "name: linked\ndescription: Linked kit\n",
+		"templates/README.md": "# {{project_name}}\n",
+	})
+	name, err := km.LinkKit(kitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "linked" {
+		t.Errorf("LinkKit = %q, want linked", name)
+	}
+	if _, err := km.LinkKit(kitPath); err == nil {
+		t.Error("linking the same kit twice succeeded")
+	}
+
+	kit, err := km.GetKit("linked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !kit.Linked || kit.LocalPath != kitPath || kit.Root != UserKitRoot {
+		t.Errorf("GetKit = linked %v, path %q, root %q", kit.Linked, kit.LocalPath, kit.Root)
+	}
+	if err := km.UpdateKit("linked"); err != nil {
+		t.Errorf("UpdateKit of a linked kit: %v", err)
+	}
+
+	// Edits show up without reinstalling; a kit that went away is broken
+	if err := os.WriteFile(filepath.Join(kitPath, "metadata.yaml"), []byte("name: linked\ndescription: Edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if kit, _ := km.GetKit("linked"); kit == nil || kit.Description != "Edited" {
+		t.Errorf("GetKit after an edit = %+v, want the edited description", kit)
+	}
+	if err := os.RemoveAll(filepath.Join(kitPath, "templates")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := km.GetKit("linked"); err == nil {
+		t.Error("GetKit of a broken linked kit succeeded")
+	}
+	kits, err := km.ListKits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed *types.Kit
+	for i := range kits {
+		if kits[i].Name == "linked" {
+			listed = &kits[i]
+		}
+	}
+	if listed == nil || !listed.Linked || listed.Broken == "" {
+		t.Errorf("ListKits = %+v, want the linked kit marked broken", kits)
+	}
+
+	// Removing only unregisters the kit
+	if err := km.RemoveKit("linked"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := km.LinkedPath("linked"); ok {
+		t.Error("RemoveKit kept the link")
+	}
+	if _, err := os.Stat(filepath.Join(kitPath, "metadata.yaml")); err != nil {
+		t.Errorf("RemoveKit touched the linked directory: %v", err)
+	}
+}
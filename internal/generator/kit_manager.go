@@ -3,15 +3,17 @@ package generator
 import (
 	"archive/tar"
 	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	gcconfig "github.com/rafa-mori/gocrafter/internal/config"
 	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 	"gopkg.in/yaml.v3"
@@ -19,24 +21,21 @@ import (
 
 // KitManagerImpl implements the KitManager interface
 type KitManagerImpl struct {
-	config   *types.KitConfig
-	kitsPath string
+	config    *types.KitConfig
+	kitsPath  string
+	roots     []KitRoot
+	downloads *DownloadCache
+	offline   bool
 }
 
-// NewKitManager creates a new kit manager instance
+// NewKitManager creates a new kit manager instance; a nil config loads the
+// user's configuration file
 func NewKitManager(config *types.KitConfig) (*KitManagerImpl, error) {
 	if config == nil {
-		// Default configuration
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
-		}
-		
-		config = &types.KitConfig{
-			KitsPath:    filepath.Join(homeDir, ".gocrafter", "kits"),
-			CachePath:   filepath.Join(homeDir, ".gocrafter", "cache"),
-			AutoUpdate:  false,
-			MaxCacheAge: 7,
+		// The user's configuration
+		var err error
+		if config, err = gcconfig.Load(); err != nil {
+			return nil, err
 		}
 	}
 
@@ -51,9 +50,23 @@ func NewKitManager(config *types.KitConfig) (*KitManagerImpl, error) {
 	return &KitManagerImpl{
 		config:   config,
 		kitsPath: config.KitsPath,
+		roots:    discoverKitRoots(config.KitsPath),
+		downloads: NewDownloadCache(config.CachePath,
+			time.Duration(config.MaxCacheAge)*24*time.Hour, int64(config.MaxCacheSize)<<20),
 	}, nil
 }
 
+// Downloads returns the cache of downloaded kits
+func (km *KitManagerImpl) Downloads() *DownloadCache {
+	return km.downloads
+}
+
+// SetOffline makes the kit manager serve registry indexes and kit downloads
+// from the cache only
+func (km *KitManagerImpl) SetOffline(offline bool) {
+	km.offline = offline
+}
+
 // AddKit adds a new kit from repository URL
 func (km *KitManagerImpl) AddKit(repoURL string) error {
 	gl.Log("info", fmt.Sprintf("Adding kit from repository: %s", repoURL))
@@ -64,12 +77,20 @@ func (km *KitManagerImpl) AddKit(repoURL string) error {
 		return fmt.Errorf("could not extract kit name from URL: %s", repoURL)
 	}
 
+	return km.addKit(kitName, repoURL)
+}
+
+// addKit downloads the kit at repoURL and installs it as kitName
+func (km *KitManagerImpl) addKit(kitName, repoURL string) error {
 	kitPath := filepath.Join(km.kitsPath, kitName)
 
 	// Check if kit already exists
 	if _, err := os.Stat(kitPath); err == nil {
 		return fmt.Errorf("kit '%s' already exists. Use update command to update it", kitName)
 	}
+	if _, ok := km.LinkedPath(kitName); ok {
+		return fmt.Errorf("kit '%s' already exists as a linked kit", kitName)
+	}
 
 	// Clone or download the kit
 	if err := km.downloadKit(repoURL, kitPath); err != nil {
@@ -87,11 +108,29 @@ func (km *KitManagerImpl) AddKit(repoURL string) error {
 	return nil
 }
 
-// RemoveKit removes a kit by name
+// RemoveKit removes a kit by name. Linked kits are only unregistered; their
+// directory is never touched.
 func (km *KitManagerImpl) RemoveKit(name string) error {
+	root, name := splitKitName(name)
+	if root != "" && root != UserKitRoot {
+		return fmt.Errorf("only user kits can be removed, not %s kits", root)
+	}
+
+	if _, ok := km.LinkedPath(name); ok {
+		if err := km.unlinkKit(name); err != nil {
+			return fmt.Errorf("failed to unlink kit '%s': %w", name, err)
+		}
+		gl.Log("info", fmt.Sprintf("Kit '%s' unlinked", name))
+		return nil
+	}
+
 	kitPath := filepath.Join(km.kitsPath, name)
-	
+
 	if _, err := os.Stat(kitPath); os.IsNotExist(err) {
+		// Kits of the other roots aren't managed by gocrafter
+		if kit, err := km.GetKit(name); err == nil {
+			return fmt.Errorf("kit '%s' is in the %s kits (%s); remove it there", name, kit.Root, kit.LocalPath)
+		}
 		return fmt.Errorf("kit '%s' not found", name)
 	}
 
@@ -103,49 +142,53 @@ func (km *KitManagerImpl) RemoveKit(name string) error {
 	return nil
 }
 
-// ListKits returns all available kits
+// ListKits returns the kits of every root, sorted by name and then by
+// precedence. A kit hidden by one of the same name in an earlier root is
+// included, marked as shadowed. Linked kits whose directory can't be loaded
+// are included, marked as broken.
 func (km *KitManagerImpl) ListKits() ([]types.Kit, error) {
 	var kits []types.Kit
 
-	entries, err := os.ReadDir(km.kitsPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read kits directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		kitPath := filepath.Join(km.kitsPath, entry.Name())
-		kit, err := km.loadKitMetadata(kitPath)
+	found := make(map[string]string)
+	for _, root := range km.roots {
+		rootKits, err := km.listRoot(root)
 		if err != nil {
-			gl.Log("warn", fmt.Sprintf("Failed to load kit metadata for '%s': %v", entry.Name(), err))
+			if root.Name == UserKitRoot {
+				return nil, err
+			}
+			gl.Log("warn", fmt.Sprintf("Skipping %s kits: %v", root.Name, err))
 			continue
 		}
 
-		kit.LocalPath = kitPath
-		kits = append(kits, *kit)
+		names := make([]string, 0, len(rootKits))
+		for name := range rootKits {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			kit := rootKits[name]
+			if shadowing, ok := found[name]; ok {
+				kit.ShadowedBy = shadowing
+			} else {
+				found[name] = root.Name
+			}
+			kits = append(kits, kit)
+		}
 	}
+	sort.SliceStable(kits, func(i, j int) bool {
+		return kits[i].Name < kits[j].Name
+	})
 
 	return kits, nil
 }
 
-// GetKit returns a specific kit by name
+// GetKit returns a specific kit by name, from the first root that has it.
+// A name qualified with a root, such as "system:go-api", is only looked up
+// in that root.
 func (km *KitManagerImpl) GetKit(name string) (*types.Kit, error) {
-	kitPath := filepath.Join(km.kitsPath, name)
-	
-	if _, err := os.Stat(kitPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("kit '%s' not found", name)
-	}
-
-	kit, err := km.loadKitMetadata(kitPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load kit metadata: %w", err)
-	}
-
-	kit.LocalPath = kitPath
-	return kit, nil
+	root, kitName := splitKitName(name)
+	return km.findKit(root, kitName)
 }
 
 // UpdateKit updates an existing kit
@@ -155,12 +198,22 @@ func (km *KitManagerImpl) UpdateKit(name string) error {
 		return err
 	}
 
+	// Linked kits are read from their directory, so they are always current
+	if kit.Linked {
+		gl.Log("info", fmt.Sprintf("Kit '%s' is linked to %s; nothing to update", name, kit.LocalPath))
+		return nil
+	}
+
+	if kit.Root != UserKitRoot {
+		return fmt.Errorf("kit '%s' is in the %s kits (%s); only user kits are updated", name, kit.Root, kit.LocalPath)
+	}
+
 	if kit.Repository == "" {
 		return fmt.Errorf("kit '%s' has no repository URL configured", name)
 	}
 
 	// Backup current kit
-	backupPath := filepath.Join(km.config.CachePath, fmt.Sprintf("%s_backup_%d", name, time.Now().Unix()))
+	backupPath := filepath.Join(km.config.CachePath, fmt.Sprintf("%s_backup_%d", filepath.Base(kit.LocalPath), time.Now().Unix()))
 	if err := km.copyDir(kit.LocalPath, backupPath); err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
@@ -191,6 +244,51 @@ func (km *KitManagerImpl) UpdateKit(name string) error {
 	return nil
 }
 
+// RefreshStaleKit updates a user kit installed from a repository when
+// auto_update is on and it is older than max_cache_age_days, unless that is
+// zero. It reports
+// whether the kit was updated; if the update fails, the installed kit is
+// left as it was.
+func (km *KitManagerImpl) RefreshStaleKit(name string) (bool, error) {
+	if !km.config.AutoUpdate {
+		return false, nil
+	}
+	// A kit that can't be found is reported by whoever uses it
+	kit, err := km.GetKit(name)
+	if err != nil || kit.Linked || kit.Root != UserKitRoot || kit.Repository == "" {
+		return false, nil
+	}
+
+	// A zero age limit means kits never go stale
+	maxAge := time.Duration(km.config.MaxCacheAge) * 24 * time.Hour
+	if maxAge <= 0 || kit.InstallDate.IsZero() || time.Since(kit.InstallDate) < maxAge {
+		return false, nil
+	}
+
+	gl.Log("info", fmt.Sprintf("Kit '%s' is older than %d days; updating it", name, km.config.MaxCacheAge))
+	if err := km.UpdateKit(name); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LoadKit loads the kit in kitPath in place, without installing it
+func (km *KitManagerImpl) LoadKit(kitPath string) (*types.Kit, error) {
+	if err := km.ValidateKit(kitPath); err != nil {
+		return nil, err
+	}
+
+	kit, err := km.loadKitMetadata(kitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if kit.LocalPath, err = filepath.Abs(kitPath); err != nil {
+		return nil, fmt.Errorf("failed to resolve kit path: %w", err)
+	}
+	return kit, nil
+}
+
 // ValidateKit validates kit structure and metadata
 func (km *KitManagerImpl) ValidateKit(kitPath string) error {
 	// Check if metadata.yaml exists
@@ -226,13 +324,13 @@ func (km *KitManagerImpl) extractKitNameFromURL(repoURL string) string {
 			return strings.TrimSuffix(name, ".git")
 		}
 	}
-	
+
 	// Fallback: use last part of URL path
 	parts := strings.Split(strings.TrimSuffix(repoURL, "/"), "/")
 	if len(parts) > 0 {
 		return strings.TrimSuffix(parts[len(parts)-1], ".git")
 	}
-	
+
 	return ""
 }
 
@@ -241,14 +339,31 @@ func (km *KitManagerImpl) downloadKit(repoURL, targetPath string) error {
 	if km.isLocalPath(repoURL) {
 		return km.copyLocalKit(repoURL, targetPath)
 	}
-	
-	// Try git clone first
-	if err := km.gitClone(repoURL, targetPath); err == nil {
-		return nil
+
+	// Archives are downloaded over HTTP; anything else is cloned with git,
+	// falling back to HTTP for URLs serving a .tar.gz under another name
+	var err error
+	if strings.HasSuffix(repoURL, ".tar.gz") {
+		err = km.httpDownload(repoURL, targetPath)
+	} else if err = km.gitClone(repoURL, targetPath); err != nil && isHTTPURL(repoURL) {
+		os.RemoveAll(targetPath)
+		if httpErr := km.httpDownload(repoURL, targetPath); httpErr != nil {
+			err = errors.Join(err, httpErr)
+		} else {
+			err = nil
+		}
 	}
 
-	// Fallback to HTTP download for archive formats
-	return km.httpDownload(repoURL, targetPath)
+	// Don't leave a partial kit behind
+	if err != nil {
+		os.RemoveAll(targetPath)
+	}
+	return err
+}
+
+// isHTTPURL reports whether url is fetched over HTTP(S)
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")
 }
 
 func (km *KitManagerImpl) isLocalPath(path string) bool {
@@ -274,46 +389,60 @@ func (km *KitManagerImpl) copyLocalKit(sourcePath, targetPath string) error {
 }
 
 func (km *KitManagerImpl) gitClone(repoURL, targetPath string) error {
-	cmd := exec.Command("git", "clone", repoURL, targetPath)
+	mirror, err := km.downloads.Mirror(repoURL, km.offline)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "clone", "--quiet", mirror, targetPath)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("git clone failed: %w", err)
 	}
-	
+
 	// Remove .git directory to save space
 	gitDir := filepath.Join(targetPath, ".git")
 	os.RemoveAll(gitDir)
-	
+
 	return nil
 }
 
+// httpDownload downloads a .tar.gz archive, whatever the URL's suffix, and
+// extracts it into targetPath
 func (km *KitManagerImpl) httpDownload(repoURL, targetPath string) error {
-	// This is a simplified implementation for tar.gz archives
-	if !strings.HasSuffix(repoURL, ".tar.gz") {
-		return fmt.Errorf("unsupported archive format, only .tar.gz supported for HTTP download")
-	}
-
-	resp, err := http.Get(repoURL)
+	archive, err := km.downloads.Archive(repoURL, km.offline)
 	if err != nil {
-		return fmt.Errorf("failed to download archive: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download archive: HTTP %d", resp.StatusCode)
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open cached archive: %w", err)
 	}
+	defer file.Close()
 
 	// Extract tar.gz
-	return km.extractTarGz(resp.Body, targetPath)
+	return km.extractTarGz(file, targetPath)
 }
 
+// extractTarGz extracts a kit archive into targetPath. Entries must stay
+// inside targetPath: absolute or escaping names, symlinks pointing outside it
+// and writes through symlinks are rejected.
 func (km *KitManagerImpl) extractTarGz(src io.Reader, targetPath string) error {
 	gzr, err := gzip.NewReader(src)
 	if err != nil {
-		return err
+		return fmt.Errorf("unsupported archive format, only .tar.gz is supported: %w", err)
 	}
 	defer gzr.Close()
 
+	root, err := filepath.Abs(targetPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+
 	tr := tar.NewReader(gzr)
+	var links []string
 
 	for {
 		header, err := tr.Next()
@@ -324,31 +453,123 @@ func (km *KitManagerImpl) extractTarGz(src io.Reader, targetPath string) error {
 			return err
 		}
 
-		path := filepath.Join(targetPath, header.Name)
-		
+		path, err := archiveEntryPath(root, header.Name)
+		if err != nil {
+			return err
+		}
+		if path == root {
+			continue
+		}
+
 		switch header.Typeflag {
 		case tar.TypeDir:
-			if err := os.MkdirAll(path, 0755); err != nil {
+			if err := mkdirInRoot(root, path); err != nil {
 				return err
 			}
 		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			if err := mkdirInRoot(root, filepath.Dir(path)); err != nil {
 				return err
 			}
-			
-			file, err := os.Create(path)
+			if err := refuseSymlink(path); err != nil {
+				return err
+			}
+
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
 			if err != nil {
 				return err
 			}
-			
+
 			if _, err := io.Copy(file, tr); err != nil {
 				file.Close()
 				return err
 			}
 			file.Close()
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) || strings.HasPrefix(header.Linkname, "/") {
+				return fmt.Errorf("archive symlink %s points to absolute path %s", header.Name, header.Linkname)
+			}
+			if !withinRoot(root, filepath.Join(filepath.Dir(path), header.Linkname)) {
+				return fmt.Errorf("archive symlink %s points outside the kit: %s", header.Name, header.Linkname)
+			}
+			if err := mkdirInRoot(root, filepath.Dir(path)); err != nil {
+				return err
+			}
+			if _, err := os.Lstat(path); err == nil {
+				return fmt.Errorf("archive symlink %s replaces an existing entry", header.Name)
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+			links = append(links, path)
+		}
+	}
+
+	// Links through other links, e.g. "b -> a/.." with "a -> .", can still
+	// leave the root once every entry exists
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		resolved, err := filepath.EvalSymlinks(link)
+		if err != nil {
+			continue // Dangling links point nowhere
+		}
+		if !withinRoot(realRoot, resolved) {
+			return fmt.Errorf("archive symlink %s points outside the kit", link)
+		}
+	}
+
+	return nil
+}
+
+// archiveEntryPath returns where the archive entry name is extracted below
+// root, rejecting absolute names and names that escape root
+func archiveEntryPath(root, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':') {
+		return "", fmt.Errorf("archive entry %s has an absolute path", name)
+	}
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if !withinRoot(root, path) {
+		return "", fmt.Errorf("archive entry %s escapes the kit directory", name)
+	}
+	return path, nil
+}
+
+// withinRoot reports whether path is root or below it, lexically
+func withinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// mkdirInRoot creates dir below root, refusing to traverse a symlink on the
+// way, so archive entries can't be written through a link extracted earlier
+func mkdirInRoot(root, dir string) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		if err := refuseSymlink(current); err != nil {
+			return err
+		}
+		if err := os.Mkdir(current, 0755); err != nil && !os.IsExist(err) {
+			return err
 		}
 	}
+	return nil
+}
 
+// refuseSymlink fails if path exists and is a symlink
+func refuseSymlink(path string) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("archive entry %s would be written through a symlink", path)
+	}
 	return nil
 }
 
@@ -372,6 +593,11 @@ func (km *KitManagerImpl) loadKitMetadata(kitPath string) (*types.Kit, error) {
 		return nil, fmt.Errorf("kit description is required in metadata")
 	}
 
+	// Installing or updating a kit writes its metadata
+	if info, err := os.Stat(metadataPath); err == nil {
+		kit.InstallDate = info.ModTime()
+	}
+
 	return &kit, nil
 }
 
@@ -392,6 +618,15 @@ func (km *KitManagerImpl) copyDir(src, dst string) error {
 			return os.MkdirAll(dstPath, info.Mode())
 		}
 
+		// Copy symlinks as links so kits can ship them
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dstPath)
+		}
+
 		srcFile, err := os.Open(path)
 		if err != nil {
 			return err
@@ -402,7 +637,7 @@ func (km *KitManagerImpl) copyDir(src, dst string) error {
 			return err
 		}
 
-		dstFile, err := os.Create(dstPath)
+		dstFile, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
 		if err != nil {
 			return err
 		}
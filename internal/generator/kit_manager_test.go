@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// archiveEntry is a tar entry: a file with content, a directory (name ending
+// in /) or a symlink when link is set
+type archiveEntry struct {
+	name, content, link string
+}
+
+func tarGz(t *testing.T, entries []archiveEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		switch {
+		case e.link != "":
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, e.link, 0
+		case e.name[len(e.name)-1] == '/':
+			header.Typeflag, header.Mode, header.Size = tar.TypeDir, 0755, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarGz(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "kit")
+	archive := tarGz(t, []archiveEntry{
+		{name: "./"},
+		{name: "metadata.yaml", content: "name: demo\n"},
+		{name: "templates/"},
+		{name: "templates/README.md", content: "# demo\n"},
+		{name: "templates/docs/README.md", link: "../README.md"},
+	})
+	if err := (&KitManagerImpl{}).extractTarGz(archive, target); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(target, "templates", "docs", "README.md"))
+	if err != nil || string(data) != "# demo\n" {
+		t.Errorf("link inside the kit = %q, %v", data, err)
+	}
+}
+
+func TestExtractTarGzRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+	}{
+		{"absolute name", []archiveEntry{{name: "/tmp/evil", content: "x"}}},
+		{"dot-dot name", []archiveEntry{{name: "../evil", content: "x"}}},
+		{"nested dot-dot name", []archiveEntry{{name: "templates/../../evil", content: "x"}}},
+		{"absolute link", []archiveEntry{{name: "x", link: "/etc"}, {name: "x/passwd", content: "x"}}},
+		{"escaping link", []archiveEntry{{name: "x", link: "../.."}, {name: "x/evil", content: "x"}}},
+		{"write through a link", []archiveEntry{{name: "sub/"}, {name: "x", link: "sub"}, {name: "x/evil", content: "x"}}},
+		{"file over a link", []archiveEntry{{name: "x", link: "sub"}, {name: "x", content: "x"}}},
+		{"chained links", []archiveEntry{{name: "b", link: "a/.."}, {name: "a", link: "."}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "kits", "kit")
+			if err := (&KitManagerImpl{}).extractTarGz(tarGz(t, tt.entries), target); err == nil {
+				t.Error("extractTarGz succeeded, want an error")
+			}
+			for _, name := range []string{"evil", filepath.Join("kits", "evil"), "passwd"} {
+				if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+					t.Errorf("%s was written outside the kit", name)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadKitFallsBackToHTTP(t *testing.T) {
+	archive := tarGz(t, []archiveEntry{
+		{name: "metadata.yaml", content: "name: served\n"},
+		{name: "templates/README.md", content: "# {{project_name}}\n"},
+	}).Bytes()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tarball/main":
+			w.Write(archive)
+		case "/page":
+			w.Write([]byte("<html>not a kit</html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	t.Setenv(KitsPathEnv, "")
+	dir := t.TempDir()
+	km, err := NewKitManager(&types.KitConfig{
+		KitsPath:  filepath.Join(dir, "kits"),
+		CachePath: filepath.Join(dir, "cache"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not a git repository, but a tarball without the .tar.gz suffix
+	target := filepath.Join(dir, "kits", "served")
+	if err := km.downloadKit(server.URL+"/tarball/main", target); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "templates", "README.md")); err != nil {
+		t.Errorf("downloaded kit is incomplete: %v", err)
+	}
+
+	// When both fail, both errors are reported and nothing is left behind
+	target = filepath.Join(dir, "kits", "page")
+	err = km.downloadKit(server.URL+"/page", target)
+	if err == nil {
+		t.Fatal("downloadKit of an HTML page succeeded")
+	}
+	for _, want := range []string{"git", ".tar.gz"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %s", err, want)
+		}
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("a failed download left the kit directory behind")
+	}
+}
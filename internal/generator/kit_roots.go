@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	gl "github.com/rafa-mori/gocrafter/logger"
+)
+
+// KitsPathEnv names directories (separated like PATH) searched for kits after
+// the project's own kits and before the user's
+const KitsPathEnv = "GOCRAFTER_KITS_PATH"
+
+// SystemKitsPath is the directory of kits installed for every user
+const SystemKitsPath = "/usr/share/gocrafter/kits"
+
+// Kit root names, in precedence order
+const (
+	ProjectKitRoot = "project" // .gocrafter/kits of the enclosing repository
+	EnvKitRoot     = "env"     // $GOCRAFTER_KITS_PATH entries
+	UserKitRoot    = "user"    // The kits path, where kit add installs
+	SystemKitRoot  = "system"  // SystemKitsPath
+)
+
+// KitRoot is a directory kits are read from
+type KitRoot struct {
+	Name string // One of the kit root names
+	Path string
+}
+
+// discoverKitRoots returns the kit roots in precedence order. userPath is
+// always included; the other roots only if they exist.
+func discoverKitRoots(userPath string) []KitRoot {
+	var roots []KitRoot
+	seen := map[string]bool{filepath.Clean(userPath): true}
+	add := func(name, path string) {
+		if path == "" {
+			return
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if seen[path] || !isDirectory(path) {
+			return
+		}
+		seen[path] = true
+		roots = append(roots, KitRoot{Name: name, Path: path})
+	}
+
+	add(ProjectKitRoot, projectKitsPath())
+	for _, dir := range filepath.SplitList(os.Getenv(KitsPathEnv)) {
+		add(EnvKitRoot, dir)
+	}
+	roots = append(roots, KitRoot{Name: UserKitRoot, Path: userPath})
+	add(SystemKitRoot, SystemKitsPath)
+	return roots
+}
+
+// projectKitsPath returns the .gocrafter/kits directory of the working
+// directory or its nearest ancestor, looking no further up than the
+// repository root
+func projectKitsPath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, ".gocrafter", "kits")
+		if isDirectory(candidate) {
+			return candidate
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func isDirectory(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Roots returns the kit roots, in precedence order
+func (km *KitManagerImpl) Roots() []KitRoot {
+	return km.roots
+}
+
+// QualifiedKitName addresses the kit name in a specific root, as GetKit accepts
+func QualifiedKitName(root, name string) string {
+	return root + ":" + name
+}
+
+// splitKitName splits a kit name into its root, if it is qualified with one,
+// and the name
+func splitKitName(name string) (root, kitName string) {
+	if prefix, rest, ok := strings.Cut(name, ":"); ok {
+		switch prefix {
+		case ProjectKitRoot, EnvKitRoot, UserKitRoot, SystemKitRoot:
+			return prefix, rest
+		}
+	}
+	return "", name
+}
+
+// findKit returns the kit name in the first root that has it, or in root
+// if one is given
+func (km *KitManagerImpl) findKit(root, name string) (*types.Kit, error) {
+	for _, kitRoot := range km.roots {
+		if root != "" && kitRoot.Name != root {
+			continue
+		}
+
+		if kitRoot.Name == UserKitRoot {
+			if linkPath, ok := km.LinkedPath(name); ok {
+				kit, err := km.loadLinkedKit(name, linkPath)
+				if err != nil {
+					return nil, fmt.Errorf("linked kit '%s' is broken: %w", name, err)
+				}
+				kit.Root = kitRoot.Name
+				return kit, nil
+			}
+		}
+
+		kitPath := filepath.Join(kitRoot.Path, name)
+		if _, err := os.Stat(kitPath); os.IsNotExist(err) {
+			continue
+		}
+
+		kit, err := km.loadKitMetadata(kitPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kit metadata: %w", err)
+		}
+
+		kit.LocalPath = kitPath
+		kit.Root = kitRoot.Name
+		return kit, nil
+	}
+
+	if root != "" {
+		return nil, fmt.Errorf("kit '%s' not found in the %s kits", name, root)
+	}
+	return nil, fmt.Errorf("kit '%s' not found", name)
+}
+
+// listRoot returns the kits of a root, keyed by the name GetKit finds them by
+func (km *KitManagerImpl) listRoot(root KitRoot) (map[string]types.Kit, error) {
+	kits := make(map[string]types.Kit)
+
+	entries, err := os.ReadDir(root.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kits directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		kitPath := filepath.Join(root.Path, entry.Name())
+		kit, err := km.loadKitMetadata(kitPath)
+		if err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to load kit metadata for '%s': %v", kitPath, err))
+			continue
+		}
+
+		kit.LocalPath = kitPath
+		kit.Root = root.Name
+		kits[entry.Name()] = *kit
+	}
+
+	if root.Name != UserKitRoot {
+		return kits, nil
+	}
+	links, err := km.loadLinks()
+	if err != nil {
+		return nil, err
+	}
+	for name, kitPath := range links {
+		kit, _ := km.loadLinkedKit(name, kitPath)
+		kit.Root = root.Name
+		kits[name] = *kit
+	}
+	return kits, nil
+}
@@ -0,0 +1,147 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// kitFiles returns the files of a minimal kit named name below root
+func kitFiles(root, name, description string) map[string]string {
+	return map[string]string{
+		root + "/" + name + "/metadata.yaml":       "name: " + name + "\ndescription: " + description + "\n",
+		root + "/" + name + "/templates/README.md": "# " + name + "\n",
+	}
+}
+
+// layeredKits creates a repository with project kits, an environment root and
+// a user root, each holding a kit named shared, and enters a subdirectory of
+// the repository
+func layeredKits(t *testing.T) *KitManagerImpl {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{"repo/.git/HEAD": "ref: refs/heads/main\n"}
+	for _, kits := range []map[string]string{
+		kitFiles("repo/.gocrafter/kits", "shared", "project"),
+		kitFiles("env", "shared", "env"),
+		kitFiles("env", "envonly", "env"),
+		kitFiles("user", "shared", "user"),
+		kitFiles("user", "mine", "user"),
+	} {
+		for name, content := range kits {
+			files[name] = content
+		}
+	}
+	writeKit(t, dir, files)
+	if err := os.MkdirAll(filepath.Join(dir, "repo", "svc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(filepath.Join(dir, "repo", "svc"))
+	t.Setenv(KitsPathEnv, filepath.Join(dir, "env")+string(os.PathListSeparator)+filepath.Join(dir, "missing"))
+	km, err := NewKitManager(&types.KitConfig{
+		KitsPath:  filepath.Join(dir, "user"),
+		CachePath: filepath.Join(dir, "cache"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return km
+}
+
+func TestKitRoots(t *testing.T) {
+	km := layeredKits(t)
+
+	var names []string
+	for _, root := range km.Roots() {
+		if root.Name != SystemKitRoot {
+			names = append(names, root.Name)
+		}
+	}
+	if want := []string{ProjectKitRoot, EnvKitRoot, UserKitRoot}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Roots = %v, want %v", names, want)
+	}
+
+	// The project root stops at the repository
+	t.Chdir(t.TempDir())
+	if path := projectKitsPath(); path != "" {
+		t.Errorf("projectKitsPath outside the repository = %q", path)
+	}
+}
+
+func TestGetKitShadowing(t *testing.T) {
+	km := layeredKits(t)
+
+	tests := []struct {
+		name string
+		root string // Root the kit is found in; empty when not found
+		desc string
+	}{
+		{"shared", ProjectKitRoot, "project"},
+		{"envonly", EnvKitRoot, "env"},
+		{"mine", UserKitRoot, "user"},
+		{QualifiedKitName(EnvKitRoot, "shared"), EnvKitRoot, "env"},
+		{QualifiedKitName(UserKitRoot, "shared"), UserKitRoot, "user"},
+		{QualifiedKitName(UserKitRoot, "envonly"), "", ""},
+		{"missing", "", ""},
+		// Unknown prefixes are part of the name
+		{"other:shared", "", ""},
+	}
+	for _, tt := range tests {
+		kit, err := km.GetKit(tt.name)
+		if tt.root == "" {
+			if err == nil {
+				t.Errorf("GetKit(%q) found the %s kit", tt.name, kit.Root)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("GetKit(%q): %v", tt.name, err)
+			continue
+		}
+		if kit.Root != tt.root || kit.Description != tt.desc {
+			t.Errorf("GetKit(%q) = %s kit %q, want %s kit %q", tt.name, kit.Root, kit.Description, tt.root, tt.desc)
+		}
+	}
+
+	kits, err := km.ListKits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, kit := range kits {
+		if kit.Root != SystemKitRoot {
+			got = append(got, strings.Join([]string{kit.Root, kit.Name, kit.ShadowedBy}, ":"))
+		}
+	}
+	want := []string{
+		"env:envonly:",
+		"user:mine:",
+		"project:shared:",
+		"env:shared:project",
+		"user:shared:project",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListKits = %q, want %q", got, want)
+	}
+}
+
+func TestSplitKitName(t *testing.T) {
+	tests := []struct {
+		in, root, name string
+	}{
+		{"go-api", "", "go-api"},
+		{"user:go-api", UserKitRoot, "go-api"},
+		{"project:go-api", ProjectKitRoot, "go-api"},
+		{"github:go-api", "", "github:go-api"},
+	}
+	for _, tt := range tests {
+		if root, name := splitKitName(tt.in); root != tt.root || name != tt.name {
+			t.Errorf("splitKitName(%q) = %q, %q; want %q, %q", tt.in, root, name, tt.root, tt.name)
+		}
+	}
+}
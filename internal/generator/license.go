@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rafa-mori/gocrafter/internal/license"
+)
+
+// LicenseOptions describes the license applied to a generated project
+type LicenseOptions struct {
+	ID        string // Catalog identifier, see license.Lookup; empty or "none" skips
+	Holder    string // Copyright holder
+	GoHeaders bool   // Inject a copyright/SPDX header into every .go file
+}
+
+// ApplyLicense writes LICENSE (unless the template already produced one) and
+// optionally injects license headers into generated Go files
+func ApplyLicense(projectPath string, opts LicenseOptions) error {
+	if opts.ID == "" || strings.EqualFold(opts.ID, "none") {
+		return nil
+	}
+
+	l, ok := license.Lookup(opts.ID)
+	if !ok {
+		_, err := license.Validate(opts.ID)
+		return err
+	}
+	year := time.Now().Year()
+
+	licensePath := filepath.Join(projectPath, "LICENSE")
+	if _, err := os.Stat(licensePath); os.IsNotExist(err) {
+		text, err := l.Text(opts.Holder, year)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(licensePath, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write LICENSE: %w", err)
+		}
+	}
+
+	if !opts.GoHeaders {
+		return nil
+	}
+
+	header := l.GoHeader(opts.Holder, year)
+	return filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != projectPath && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		injected := license.InjectGoHeader(src, header)
+		if bytes.Equal(injected, src) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, injected, info.Mode().Perm())
+	})
+}
@@ -0,0 +1,193 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/engine"
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+// LintIssue describes a problem found while linting a kit
+type LintIssue struct {
+	File     string // Path relative to the kit root, empty for kit-level issues
+	Line     int
+	Severity string // "error" or "warn"
+	Message  string
+}
+
+// String formats the issue as file:line: severity: message
+func (i LintIssue) String() string {
+	location := i.File
+	if location != "" && i.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, i.Line)
+	}
+	if location == "" {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, i.Severity, i.Message)
+}
+
+// TemplateIssue locates a generation error in the kit in kitFS, if it is a
+// template file or partial that failed to parse or execute (see
+// KitGenerator.WithStrict)
+func TemplateIssue(kitFS fs.FS, err error) (LintIssue, bool) {
+	var templateErr *engine.TemplateError
+	if !errors.As(err, &templateErr) {
+		return LintIssue{}, false
+	}
+
+	file := path.Join(engine.KitTemplatesDir, templateErr.Name)
+	if templateErr.Partial {
+		// Partial names have no extension
+		file = path.Join(engine.PartialsDir, templateErr.Name)
+		if matches, _ := fs.Glob(kitFS, file+".*"); len(matches) == 1 {
+			file = matches[0]
+		}
+	}
+	return LintIssue{
+		File:     file,
+		Line:     templateErr.Line,
+		Severity: "error",
+		Message:  templateErr.Message,
+	}, true
+}
+
+// partialRefPattern matches template/include calls between the given delimiters
+func partialRefPattern(delims types.Delimiters) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(delims.Left) + `-?\s*(?:template|include)\s+"([^"]+)"`)
+}
+
+// definePattern matches define and block actions between the given delimiters
+func definePattern(delims types.Delimiters) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(delims.Left) + `-?\s*(?:define|block)\s+"([^"]+)"`)
+}
+
+// LintKit checks a kit directory for problems that only show up at
+// generation time, such as references to partials that do not exist
+func (km *KitManagerImpl) LintKit(kitPath string) ([]LintIssue, error) {
+	var issues []LintIssue
+
+	if err := km.ValidateKit(kitPath); err != nil {
+		issues = append(issues, LintIssue{Severity: "error", Message: err.Error()})
+		return issues, nil
+	}
+
+	kit, err := km.loadKitMetadata(kitPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.ValidateDelimiters(kit); err != nil {
+		issues = append(issues, LintIssue{File: "metadata.yaml", Severity: "error", Message: err.Error()})
+		return issues, nil
+	}
+
+	if _, err := selectPostProcessors(kit.Language, kit.PostProcess); err != nil {
+		issues = append(issues, LintIssue{File: "metadata.yaml", Severity: "error", Message: err.Error()})
+	}
+
+	partials, err := engine.LoadPartials(engine.DirFS(kitPath), engine.PartialsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// References are reported after the walk, once the templates defined
+	// inside partials, which are attached to every template, are known
+	type reference struct {
+		file string
+		line int
+		name string
+	}
+	var refs []reference
+	definedBy := make(map[string]string)
+	for _, dir := range []string{engine.KitTemplatesDir, engine.PartialsDir} {
+		root := filepath.Join(kitPath, dir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+				return err
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			relPath, _ := filepath.Rel(kitPath, path)
+			dirRelPath, _ := filepath.Rel(root, path)
+			delims := engine.DelimitersFor(kit, filepath.ToSlash(dirRelPath))
+
+			// Names defined in a template file only exist in that file
+			local := make(map[string]bool)
+			for _, match := range definePattern(delims).FindAllStringSubmatch(string(content), -1) {
+				if dir == engine.PartialsDir {
+					partial := filepath.ToSlash(strings.TrimSuffix(dirRelPath, filepath.Ext(dirRelPath)))
+					if _, ok := definedBy[match[1]]; !ok {
+						definedBy[match[1]] = partial
+					}
+				}
+				local[match[1]] = true
+			}
+
+			pattern := partialRefPattern(delims)
+			for lineNo, line := range strings.Split(string(content), "\n") {
+				for _, match := range pattern.FindAllStringSubmatch(line, -1) {
+					if !local[match[1]] {
+						refs = append(refs, reference{filepath.ToSlash(relPath), lineNo + 1, match[1]})
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, ref := range refs {
+		if partials.Has(ref.name) {
+			used[ref.name] = true
+			continue
+		}
+		if partial, ok := definedBy[ref.name]; ok {
+			used[partial] = true
+			continue
+		}
+		issues = append(issues, LintIssue{
+			File:     ref.file,
+			Line:     ref.line,
+			Severity: "error",
+			Message:  fmt.Sprintf("partial %q not found in %s/", ref.name, engine.PartialsDir),
+		})
+	}
+
+	for _, name := range partials.Names() {
+		if !used[name] {
+			issues = append(issues, LintIssue{
+				File:     filepath.ToSlash(filepath.Join(engine.PartialsDir, name)),
+				Severity: "warn",
+				Message:  fmt.Sprintf("partial %q is never used", name),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
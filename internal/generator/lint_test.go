@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeKit creates a kit below dir from a map of relative paths to contents
+func writeKit(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLintKitPartials(t *testing.T) {
+	kitPath := t.TempDir()
+	writeKit(t, kitPath, map[string]string{
+		"metadata.yaml": "name: demo\ndescription: Demo kit\n",
+		"templates/main.go.tmpl": `package main
+{{template "header" .}}
+{{template "license" .}}
+{{define "local"}}x{{end}}{{template "local"}}
+{{template "missing" .}}
+`,
+		"templates/README.md": `{{include "docs/intro.md" .}}`,
+		// A partial that only exists for the templates it defines
+		"partials/blocks.tmpl":        `{{define "header"}}// header{{end}}{{define "license"}}// MIT{{end}}`,
+		"partials/docs/intro.md.tmpl": "intro",
+		"partials/unused.tmpl":        "unused",
+	})
+
+	issues, err := (&KitManagerImpl{}).LintKit(kitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`partials/unused: warn: partial "unused" is never used`,
+		`templates/main.go.tmpl:5: error: partial "missing" not found in partials/`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintKit = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/imports"
+)
+
+// PostProcessOptions selects the steps run after a project is generated
+type PostProcessOptions struct {
+	SkipModInit bool // Don't run go mod init when the template has no go.mod
+	SkipFormat  bool // Don't format generated .go files
+	SkipTidy    bool // Don't run go mod tidy
+	Offline     bool // Resolve modules from the local cache only (GOPROXY=off)
+}
+
+// TemplateBugError reports generated Go files that don't parse. Since the
+// input was a template, the template (not the user) has to be fixed.
+type TemplateBugError struct {
+	Files map[string]error // Keyed by path relative to the project
+}
+
+func (e *TemplateBugError) Error() string {
+	names := make([]string, 0, len(e.Files))
+	for name := range e.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "template bug: %d generated Go file(s) are not valid Go", len(names))
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %v", name, e.Files[name])
+	}
+	return b.String()
+}
+
+// goEnv returns the environment for go commands run in a generated project
+func goEnv(offline bool) []string {
+	env := append(os.Environ(), "GOFLAGS=-mod=mod")
+	if offline {
+		env = append(env, "GOPROXY=off")
+	}
+	return env
+}
+
+// runGo runs the local go toolchain in dir. A missing toolchain is reported
+// as a warning, not an error, so generation still succeeds.
+func runGo(dir string, offline bool, args ...string) error {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return errGoNotFound
+	}
+
+	cmd := exec.Command(goBin, args...)
+	cmd.Dir = dir
+	cmd.Env = goEnv(offline)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go %s: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+var errGoNotFound = fmt.Errorf("go toolchain not found in PATH")
+
+// formatGoFiles formats every .go file below root in-process and fixes
+// its imports. Files that don't parse are collected into a TemplateBugError.
+func formatGoFiles(root string) error {
+	bugs := make(map[string]error)
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || filepath.Ext(p) != ".go" {
+			return nil
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := formatGoSource(p, src)
+		if err != nil {
+			relPath, _ := filepath.Rel(root, p)
+			bugs[filepath.ToSlash(relPath)] = err
+			return nil
+		}
+		if bytes.Equal(src, formatted) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(p, formatted, info.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(bugs) > 0 {
+		return &TemplateBugError{Files: bugs}
+	}
+	return nil
+}
+
+// formatGoSource fixes the imports of src like goimports, adding missing ones
+// and removing unused ones, and formats it like gofmt
+func formatGoSource(filename string, src []byte) ([]byte, error) {
+	return imports.Process(filename, src, &imports.Options{
+		Comments:  true,
+		TabIndent: true,
+		TabWidth:  8,
+	})
+}
@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFormatGoSource(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "several unused imports",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {}
+`,
+			want: `package main
+
+func main() {}
+`,
+		},
+		{
+			name: "unused among used",
+			src: `package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() { fmt.Println(strings.ToUpper("x")) }
+`,
+			want: `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() { fmt.Println(strings.ToUpper("x")) }
+`,
+		},
+		{
+			name: "aliased imports",
+			src: `package main
+
+import (
+	str "strings"
+	unused "os"
+	_ "embed"
+)
+
+func main() { str.ToUpper("x") }
+`,
+			want: `package main
+
+import (
+	_ "embed"
+	str "strings"
+)
+
+func main() { str.ToUpper("x") }
+`,
+		},
+		{
+			name: "missing import",
+			src: `package main
+
+import "fmt"
+
+func main() { fmt.Println(strings.ToUpper("x")) }
+`,
+			want: `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+func main() { fmt.Println(strings.ToUpper("x")) }
+`,
+		},
+		{
+			name: "formatting",
+			src:  "package main\nfunc  main( ) {\nx:=1\n_ = x}\n",
+			want: "package main\n\nfunc main() {\n\tx := 1\n\t_ = x\n}\n",
+		},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGoSource(filepath.Join(dir, "main.go"), []byte(tt.src))
+			if err != nil {
+				t.Fatalf("formatGoSource: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatGoSource =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatGoSourceInvalid(t *testing.T) {
+	if _, err := formatGoSource(filepath.Join(t.TempDir(), "main.go"), []byte("package main\nfunc {")); err == nil {
+		t.Error("formatGoSource accepted invalid Go")
+	}
+}
+
+func TestSelectPostProcessors(t *testing.T) {
+	tests := []struct {
+		language string
+		optIn    []string
+		want     []string
+	}{
+		{"go", nil, []string{"gofmt"}},
+		{"Golang", nil, []string{"gofmt"}},
+		{"rust", nil, []string{"rustfmt"}},
+		{"python", nil, []string{"black"}},
+		{"TypeScript", nil, []string{"prettier"}},
+		{"node", nil, []string{"prettier"}},
+		{"", nil, nil},
+		{"cobol", nil, nil},
+		// Validators only run when listed; a list replaces the defaults
+		{"go", []string{"go-vet"}, []string{"go-vet"}},
+		{"go", []string{"gofmt", "go-vet"}, []string{"gofmt", "go-vet"}},
+		{"python", []string{"gofmt"}, []string{"gofmt"}},
+		{"go", []string{"gofmt", "none"}, nil},
+	}
+	for _, tt := range tests {
+		processors, err := selectPostProcessors(tt.language, tt.optIn)
+		if err != nil {
+			t.Errorf("selectPostProcessors(%q, %v): %v", tt.language, tt.optIn, err)
+			continue
+		}
+		var names []string
+		for _, p := range processors {
+			names = append(names, p.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("selectPostProcessors(%q, %v) = %v, want %v", tt.language, tt.optIn, names, tt.want)
+		}
+	}
+
+	if _, err := selectPostProcessors("go", []string{"gofmt", "golint"}); err == nil {
+		t.Error("an unknown post-processor was accepted")
+	}
+}
@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Post-processor kinds
+const (
+	ProcessorFormatter = "formatter" // Rewrites files; runs by default for its language
+	ProcessorValidator = "validator" // Only checks files; runs when a kit opts in
+)
+
+// Post-processor result statuses
+const (
+	ProcessorOK      = "ok"
+	ProcessorFailed  = "failed"
+	ProcessorSkipped = "skipped"
+)
+
+// PostProcessor formats or validates a generated project
+type PostProcessor struct {
+	Name      string
+	Languages []string // Lower-case Kit.Language values the processor applies to
+	Kind      string
+	// Command is the executable that must be on PATH; empty for in-process processors
+	Command string
+	// Run processes the project; processors running the go command honor opts.Offline
+	Run func(projectPath string, opts PostProcessOptions) error
+}
+
+// PostProcessResult records the outcome of one post-processor
+type PostProcessResult struct {
+	Processor string
+	Status    string
+	Message   string
+}
+
+// String formats the result for the generation summary
+func (r PostProcessResult) String() string {
+	if r.Message == "" {
+		return fmt.Sprintf("%s: %s", r.Processor, r.Status)
+	}
+	return fmt.Sprintf("%s: %s (%s)", r.Processor, r.Status, r.Message)
+}
+
+var postProcessors = make(map[string]*PostProcessor)
+
+// RegisterPostProcessor adds a processor to the registry, replacing any
+// processor with the same name
+func RegisterPostProcessor(p *PostProcessor) {
+	postProcessors[p.Name] = p
+}
+
+// PostProcessorNames returns the names of all registered processors
+func PostProcessorNames() []string {
+	names := make([]string, 0, len(postProcessors))
+	for name := range postProcessors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectPostProcessors returns the processors to run for a kit. A non-empty
+// opt-in list names processors explicitly ("none" disables all); otherwise
+// the formatters registered for the language run.
+func selectPostProcessors(language string, optIn []string) ([]*PostProcessor, error) {
+	var selected []*PostProcessor
+
+	if len(optIn) > 0 {
+		for _, name := range optIn {
+			if name == "none" {
+				return nil, nil
+			}
+			p, ok := postProcessors[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown post-processor '%s' (available: %s)", name, strings.Join(PostProcessorNames(), ", "))
+			}
+			selected = append(selected, p)
+		}
+		return selected, nil
+	}
+
+	language = strings.ToLower(language)
+	for _, name := range PostProcessorNames() {
+		p := postProcessors[name]
+		if p.Kind == ProcessorFormatter && containsString(p.Languages, language) {
+			selected = append(selected, p)
+		}
+	}
+	return selected, nil
+}
+
+// runPostProcessors runs the processors in order. Processors whose command is
+// missing are skipped; failures are recorded and don't stop later processors.
+func runPostProcessors(processors []*PostProcessor, projectPath string, opts PostProcessOptions) []PostProcessResult {
+	var results []PostProcessResult
+
+	for _, p := range processors {
+		result := PostProcessResult{Processor: p.Name, Status: ProcessorOK}
+
+		switch {
+		case opts.SkipFormat && p.Kind == ProcessorFormatter:
+			result.Status = ProcessorSkipped
+			result.Message = "formatting disabled"
+		case p.Command != "" && !commandAvailable(p.Command):
+			result.Status = ProcessorSkipped
+			result.Message = fmt.Sprintf("%s not found in PATH", p.Command)
+		default:
+			if err := p.Run(projectPath, opts); err != nil {
+				result.Status = ProcessorFailed
+				result.Message = err.Error()
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func commandAvailable(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand returns a Run function executing an external tool in the project
+func runCommand(name string, args ...string) func(string, PostProcessOptions) error {
+	return func(projectPath string, _ PostProcessOptions) error {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = projectPath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+}
+
+// runOnFiles returns a Run function passing every generated file with the
+// given extension to an external tool
+func runOnFiles(ext, name string, args ...string) func(string, PostProcessOptions) error {
+	return func(projectPath string, opts PostProcessOptions) error {
+		var files []string
+		err := filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() && filepath.Ext(path) == ext {
+				relPath, _ := filepath.Rel(projectPath, path)
+				files = append(files, relPath)
+			}
+			return nil
+		})
+		if err != nil || len(files) == 0 {
+			return err
+		}
+		return runCommand(name, append(args, files...)...)(projectPath, opts)
+	}
+}
+
+func init() {
+	RegisterPostProcessor(&PostProcessor{
+		Name:      "gofmt",
+		Languages: []string{"go", "golang"},
+		Kind:      ProcessorFormatter,
+		Run: func(projectPath string, _ PostProcessOptions) error {
+			return formatGoFiles(projectPath)
+		},
+	})
+	// Vet type-checks the project, fetching dependencies kit projects don't
+	// have yet, so it is a validator kits opt into rather than a default
+	RegisterPostProcessor(&PostProcessor{
+		Name:      "go-vet",
+		Languages: []string{"go", "golang"},
+		Kind:      ProcessorValidator,
+		Command:   "go",
+		Run: func(projectPath string, opts PostProcessOptions) error {
+			return runGo(projectPath, opts.Offline, "vet", "./...")
+		},
+	})
+	RegisterPostProcessor(&PostProcessor{
+		Name:      "rustfmt",
+		Languages: []string{"rust"},
+		Kind:      ProcessorFormatter,
+		Command:   "rustfmt",
+		Run:       runOnFiles(".rs", "rustfmt", "--edition", "2021"),
+	})
+	RegisterPostProcessor(&PostProcessor{
+		Name:      "black",
+		Languages: []string{"python"},
+		Kind:      ProcessorFormatter,
+		Command:   "black",
+		Run:       runCommand("black", "--quiet", "."),
+	})
+	RegisterPostProcessor(&PostProcessor{
+		Name:      "prettier",
+		Languages: []string{"javascript", "typescript", "js", "ts", "node"},
+		Kind:      ProcessorFormatter,
+		Command:   "prettier",
+		Run:       runCommand("prettier", "--write", "."),
+	})
+}
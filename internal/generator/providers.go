@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rafa-mori/gocrafter/internal/engine"
+)
+
+// projectData provides TemplateVars-style structured values such as
+// {{.ProjectName}} and {{range .Features}}, plus hasFeature
+type projectData struct {
+	vars *TemplateVars
+}
+
+// ProjectProvider exposes a project configuration to templates the way
+// built-in templates have always seen it
+func ProjectProvider(c *ProjectConfig) engine.DataProvider {
+	return projectData{vars: c.ToTemplateVars()}
+}
+
+// Values returns the TemplateVars fields by name
+func (p projectData) Values() map[string]any {
+	v := p.vars
+	return map[string]any{
+		"ProjectName":   v.ProjectName,
+		"ModuleName":    v.ModuleName,
+		"PackageName":   v.PackageName,
+		"DatabaseType":  v.DatabaseType,
+		"CacheType":     v.CacheType,
+		"QueueType":     v.QueueType,
+		"HasDocker":     v.HasDocker,
+		"HasKubernetes": v.HasKubernetes,
+		"HasMonitoring": v.HasMonitoring,
+		"CIType":        v.CIType,
+		"Features":      v.Features,
+		"Custom":        v.Custom,
+	}
+}
+
+// Funcs returns hasFeature, which matches features case-insensitively by substring
+func (p projectData) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"hasFeature": func(feature string) bool {
+			for _, f := range p.vars.Features {
+				if strings.Contains(strings.ToLower(f), strings.ToLower(feature)) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// Placeholders returns the configuration as flat values: the names used by
+// template conditions (name, module, database, ...), custom values, and the
+// placeholders every kit receives (project_name, package_name, ...)
+func (c *ProjectConfig) Placeholders() engine.Placeholders {
+	values := make(engine.Placeholders)
+	for name, value := range c.Custom {
+		values[name] = value
+	}
+
+	for name, value := range basePlaceholders(c.Name) {
+		values[name] = value
+	}
+
+	values["name"] = c.Name
+	values["module"] = c.Module
+	values["template"] = c.Template
+	values["database"] = c.Database
+	values["cache"] = c.Cache
+	values["queue"] = c.Queue
+	values["monitoring"] = strings.Join(c.Monitoring, ",")
+	values["docker"] = strconv.FormatBool(c.Docker)
+	values["kubernetes"] = strconv.FormatBool(c.Kubernetes)
+	values["ci"] = c.CI
+	values["features"] = strings.Join(c.Features, ",")
+	return values
+}
+
+// Providers returns the data built-in templates render with
+func (c *ProjectConfig) Providers() []engine.DataProvider {
+	return []engine.DataProvider{c.Placeholders(), ProjectProvider(c)}
+}
+
+// projectConfigFromPlaceholders maps kit placeholders onto a project
+// configuration so kits can use TemplateVars-style data and hasFeature
+func projectConfigFromPlaceholders(projectName string, values map[string]string) *ProjectConfig {
+	pick := func(names ...string) string {
+		for _, name := range names {
+			if value := values[name]; value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	c := NewProjectConfig()
+	c.Name = projectName
+	c.Module = pick("module", "module_path")
+	c.Database = pick("database", "db")
+	c.Cache = pick("cache")
+	c.Queue = pick("queue")
+	c.CI = pick("ci")
+	c.Monitoring = engine.ParseList(values["monitoring"])
+	c.Features = engine.ParseList(values["features"])
+	if value, ok := values["docker"]; ok {
+		c.Docker = isTrue(value)
+	}
+	if value, ok := values["kubernetes"]; ok {
+		c.Kubernetes = isTrue(value)
+	}
+	for name, value := range values {
+		c.Custom[name] = value
+	}
+	return c
+}
+
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "1", "on":
+		return true
+	}
+	return false
+}
+
+// basePlaceholders are derived from the project name for every project
+func basePlaceholders(projectName string) map[string]string {
+	values := map[string]string{
+		"project_name": projectName,
+		"current_year": fmt.Sprintf("%d", time.Now().Year()),
+	}
+	if projectName == "" {
+		return values
+	}
+
+	values["package_name"] = strings.ToLower(strings.ReplaceAll(projectName, "-", ""))
+	values["module_name"] = strings.ToLower(strings.ReplaceAll(projectName, " ", "-"))
+	values["class_name"] = strings.Title(strings.ReplaceAll(projectName, "-", " "))
+	values["const_name"] = strings.ToUpper(strings.ReplaceAll(projectName, "-", "_"))
+
+	// Go-specific placeholders
+	if goVersion := os.Getenv("GO_VERSION"); goVersion != "" {
+		values["go_version"] = goVersion
+	} else {
+		values["go_version"] = "1.24" // Default Go version
+	}
+	return values
+}
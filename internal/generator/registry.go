@@ -0,0 +1,308 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gcconfig "github.com/rafa-mori/gocrafter/internal/config"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// RegistriesEnv lists registry index locations, separated by commas,
+// overriding the configured registries
+const RegistriesEnv = gcconfig.RegistriesEnv
+
+const (
+	// registriesCacheDir is the cache directory holding downloaded indexes
+	registriesCacheDir = "registries"
+	// registryCacheTTL is how long a downloaded index is used before it is fetched again
+	registryCacheTTL = time.Hour
+	// registryTimeout bounds an index download
+	registryTimeout = 10 * time.Second
+)
+
+// RegistryIndex is a registry index file, in YAML or JSON, listing the kits
+// it publishes
+type RegistryIndex struct {
+	Kits []IndexEntry `yaml:"kits"`
+}
+
+// IndexEntry is a kit published in a registry index
+type IndexEntry struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+	Language    string   `yaml:"language"`
+	Versions    []string `yaml:"versions"` // Published versions, newest first
+	Source      string   `yaml:"source"`   // Repository, archive or directory kit add installs from
+	Registry    string   `yaml:"-"`        // Index the entry was read from
+}
+
+// ParseRegistryIndex parses a registry index file
+func ParseRegistryIndex(data []byte) (*RegistryIndex, error) {
+	var index RegistryIndex
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	for i, entry := range index.Kits {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("kit %d has no name", i+1)
+		}
+		if entry.Source == "" {
+			return nil, fmt.Errorf("kit '%s' has no source", entry.Name)
+		}
+	}
+	return &index, nil
+}
+
+// Registries returns the registry index locations, in precedence order
+func (km *KitManagerImpl) Registries() []string {
+	return km.config.Registries
+}
+
+// SetRegistries replaces the registry index locations
+func (km *KitManagerImpl) SetRegistries(registries []string) {
+	km.config.Registries = registries
+}
+
+// LoadIndex reads every registry index. Entries of earlier registries shadow
+// those with the same name in later ones. A registry that can't be read is
+// skipped with a warning. Offline, downloaded indexes are only read from the
+// cache.
+func (km *KitManagerImpl) LoadIndex() ([]IndexEntry, error) {
+	if len(km.config.Registries) == 0 {
+		return nil, fmt.Errorf("no kit registries configured; set $%s or use --registry", RegistriesEnv)
+	}
+
+	var (
+		entries []IndexEntry
+		loaded  int
+	)
+	seen := make(map[string]bool)
+	for _, location := range km.config.Registries {
+		data, err := km.readIndex(location)
+		if err != nil {
+			gl.Log("warn", fmt.Sprintf("Skipping registry %s: %v", location, err))
+			continue
+		}
+		index, err := ParseRegistryIndex(data)
+		if err != nil {
+			gl.Log("warn", fmt.Sprintf("Skipping registry %s: invalid index: %v", location, err))
+			continue
+		}
+		loaded++
+
+		for _, entry := range index.Kits {
+			if seen[entry.Name] {
+				continue
+			}
+			seen[entry.Name] = true
+			entry.Registry = location
+			// Relative sources in a local index are relative to the index
+			if !isRemoteLocation(location) && (strings.HasPrefix(entry.Source, "./") || strings.HasPrefix(entry.Source, "../")) {
+				entry.Source = filepath.Join(filepath.Dir(location), entry.Source)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if loaded == 0 {
+		return nil, errors.New("no kit registry could be read")
+	}
+	return entries, nil
+}
+
+// ResolveKit looks a kit up by name in the registry indexes
+func (km *KitManagerImpl) ResolveKit(name string) (*IndexEntry, error) {
+	entries, err := km.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Name == name {
+			return &entry, nil
+		}
+	}
+	return nil, fmt.Errorf("kit '%s' not found in any registry", name)
+}
+
+// AddIndexedKit installs a kit found in a registry index under its index name
+func (km *KitManagerImpl) AddIndexedKit(entry *IndexEntry) error {
+	gl.Log("info", fmt.Sprintf("Adding kit '%s' from %s", entry.Name, entry.Source))
+	return km.addKit(entry.Name, entry.Source)
+}
+
+// readIndex returns the contents of the index at location. Downloaded indexes
+// are cached and reused for registryCacheTTL; when a download fails, or
+// offline, the cached copy is used whatever its age.
+func (km *KitManagerImpl) readIndex(location string) ([]byte, error) {
+	if !isRemoteLocation(location) {
+		return os.ReadFile(location)
+	}
+
+	cachePath := km.indexCachePath(location)
+	info, statErr := os.Stat(cachePath)
+	if statErr == nil && (km.offline || time.Since(info.ModTime()) < registryCacheTTL) {
+		return os.ReadFile(cachePath)
+	}
+	if km.offline {
+		return nil, fmt.Errorf("no cached copy of the index")
+	}
+
+	data, err := downloadIndex(location)
+	if err != nil {
+		if statErr != nil {
+			return nil, err
+		}
+		gl.Log("warn", fmt.Sprintf("Using cached index of %s: %v", location, err))
+		return os.ReadFile(cachePath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+		if err := os.WriteFile(cachePath, data, 0644); err != nil {
+			gl.Log("warn", fmt.Sprintf("Failed to cache index of %s: %v", location, err))
+		}
+	}
+	return data, nil
+}
+
+// indexCachePath returns where the downloaded index at location is cached
+func (km *KitManagerImpl) indexCachePath(location string) string {
+	sum := sha256.Sum256([]byte(location))
+	return filepath.Join(km.config.CachePath, registriesCacheDir, hex.EncodeToString(sum[:8])+".yaml")
+}
+
+// downloadIndex fetches an index over HTTP
+func downloadIndex(location string) ([]byte, error) {
+	client := &http.Client{Timeout: registryTimeout}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download index: HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// isRemoteLocation reports whether a registry location is an HTTP URL rather
+// than a local path
+func isRemoteLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// SearchIndex returns the entries matching every term, tag and the language,
+// best match first. Terms match the name best, then tags, then the
+// description; all comparisons ignore case. Without terms, every entry
+// passing the filters matches, sorted by name.
+func SearchIndex(entries []IndexEntry, terms, tags []string, language string) []IndexEntry {
+	type match struct {
+		entry IndexEntry
+		score int
+	}
+
+	var matches []match
+	for _, entry := range entries {
+		if language != "" && !strings.EqualFold(entry.Language, language) {
+			continue
+		}
+		if !hasTags(entry, tags) {
+			continue
+		}
+
+		score := 0
+		for _, term := range terms {
+			termScore := scoreTerm(entry, strings.ToLower(term))
+			if termScore == 0 {
+				score = -1
+				break
+			}
+			score += termScore
+		}
+		if score < 0 {
+			continue
+		}
+		matches = append(matches, match{entry, score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry.Name < matches[j].entry.Name
+	})
+
+	results := make([]IndexEntry, len(matches))
+	for i, m := range matches {
+		results[i] = m.entry
+	}
+	return results
+}
+
+// scoreTerm rates how well a lowercase term matches an entry; 0 is no match
+func scoreTerm(entry IndexEntry, term string) int {
+	score := 0
+
+	name := strings.ToLower(entry.Name)
+	switch {
+	case name == term:
+		score += 100
+	case strings.HasPrefix(name, term):
+		score += 50
+	case strings.Contains(name, term):
+		score += 25
+	}
+
+	tagScore := 0
+	for _, tag := range entry.Tags {
+		tag = strings.ToLower(tag)
+		if tag == term {
+			tagScore = 20
+			break
+		}
+		if strings.Contains(tag, term) {
+			tagScore = 10
+		}
+	}
+	score += tagScore
+
+	if strings.Contains(strings.ToLower(entry.Description), term) {
+		score += 5
+	}
+	return score
+}
+
+// hasTags reports whether an entry carries every tag, ignoring case
+func hasTags(entry IndexEntry, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range entry.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IsIndexedName reports whether a kit add argument is a registry kit name
+// rather than a URL, a path or a template reference
+func IsIndexedName(arg string) bool {
+	return arg != "" && !strings.ContainsAny(arg, `/\:`)
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+var searchEntries = []IndexEntry{
+	{Name: "go-api", Description: "REST API in Go", Tags: []string{"http", "rest"}, Language: "go"},
+	{Name: "go-cli", Description: "Command-line tool", Tags: []string{"cli"}, Language: "Go"},
+	{Name: "api-gateway", Description: "Gateway for APIs", Tags: []string{"http", "proxy"}, Language: "rust"},
+	{Name: "py-api", Description: "FastAPI service", Tags: []string{"api", "http"}, Language: "python"},
+}
+
+func TestSearchIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		terms    []string
+		tags     []string
+		language string
+		want     []string
+	}{
+		{"no filters", nil, nil, "", []string{"api-gateway", "go-api", "go-cli", "py-api"}},
+		{"name before tag before description", []string{"api"}, nil, "", []string{"api-gateway", "py-api", "go-api"}},
+		{"every term must match", []string{"go", "cli"}, nil, "", []string{"go-cli"}},
+		{"case insensitive", []string{"REST"}, nil, "", []string{"go-api"}},
+		{"tags", nil, []string{"HTTP"}, "", []string{"api-gateway", "go-api", "py-api"}},
+		{"language", nil, nil, "go", []string{"go-api", "go-cli"}},
+		{"no match", []string{"java"}, nil, "", []string{}},
+	}
+	for _, tt := range tests {
+		names := []string{}
+		for _, entry := range SearchIndex(searchEntries, tt.terms, tt.tags, tt.language) {
+			names = append(names, entry.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: SearchIndex = %v, want %v", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestParseRegistryIndex(t *testing.T) {
+	index, err := ParseRegistryIndex([]byte(`{"kits": [{"name": "go-api", "source": "https://example.com/go-api.git", "versions": ["1.1.0", "1.0.0"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Kits) != 1 || index.Kits[0].Versions[0] != "1.1.0" {
+		t.Errorf("JSON index = %+v", index)
+	}
+
+	for _, data := range []string{
+		"kits:\n  - source: ./x\n",
+		"kits:\n  - name: x\n",
+		"kits: [",
+	} {
+		if _, err := ParseRegistryIndex([]byte(data)); err == nil {
+			t.Errorf("ParseRegistryIndex(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestLoadIndex(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "registry", "index.yaml")
+	writeKit(t, dir, map[string]string{
+		"registry/index.yaml": "kits:\n  - name: shared\n    source: ./kits/shared\n  - name: local\n    source: ../local\n",
+	})
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("kits:\n  - name: shared\n    source: https://example.com/shared.git\n  - name: remote\n    source: https://example.com/remote.git\n"))
+	}))
+	defer server.Close()
+
+	km := &KitManagerImpl{config: &types.KitConfig{
+		CachePath:  filepath.Join(dir, "cache"),
+		Registries: []string{local, server.URL, filepath.Join(dir, "missing.yaml")},
+	}}
+	entries, err := km.LoadIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, entry := range entries {
+		got[entry.Name] = entry.Source
+	}
+	want := map[string]string{
+		"shared": filepath.Join(dir, "registry", "kits", "shared"),
+		"local":  filepath.Join(dir, "local"),
+		"remote": "https://example.com/remote.git",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadIndex = %v, want %v", got, want)
+	}
+
+	// The downloaded index is cached, and used offline
+	if _, err := km.LoadIndex(); err != nil || requests != 1 {
+		t.Errorf("second LoadIndex: %v after %d requests, want the cached index", err, requests)
+	}
+	server.Close()
+	km.offline = true
+	if entry, err := km.ResolveKit("remote"); err != nil || entry.Registry != server.URL {
+		t.Errorf("offline ResolveKit = %+v, %v", entry, err)
+	}
+	if _, err := os.Stat(km.indexCachePath(server.URL)); err != nil {
+		t.Errorf("index cache: %v", err)
+	}
+
+	km.config.Registries = []string{filepath.Join(dir, "missing.yaml")}
+	if _, err := km.LoadIndex(); err == nil {
+		t.Error("LoadIndex without a readable registry succeeded")
+	}
+}
+
+func TestIsIndexedName(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"go-api":                      true,
+		"":                            false,
+		"./kits/go-api":               false,
+		"https://example.com/x.git":   false,
+		"template:api-rest":           false,
+		`C:\kits\go-api`:              false,
+		"github.com/acme/kits/go-api": false,
+	} {
+		if got := IsIndexedName(arg); got != want {
+			t.Errorf("IsIndexedName(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
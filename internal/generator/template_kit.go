@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/engine"
+	gl "github.com/rafa-mori/gocrafter/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateKitPrefix marks a kit source naming a discovered template, e.g.
+// "template:api-rest"
+const TemplateKitPrefix = "template:"
+
+// templateKitPlaceholders are the kit placeholders TemplateVars fields are
+// derived from, so a template installed as a kit prompts for them
+var templateKitPlaceholders = []string{"module", "database", "cache", "features"}
+
+// InstallTemplate installs a template as a regular kit. Its files become the
+// kit's templates, its partials the kit's partials and its metadata the
+// kit's metadata.yaml.
+func (km *KitManagerImpl) InstallTemplate(tmpl *DiscoveredTemplate) error {
+	gl.Log("info", fmt.Sprintf("Installing template '%s' as a kit", tmpl.Name))
+
+	kitPath := filepath.Join(km.kitsPath, tmpl.Name)
+	if _, err := os.Stat(kitPath); err == nil {
+		return fmt.Errorf("kit '%s' already exists. Use update command to update it", tmpl.Name)
+	}
+
+	kit := tmpl.Info.Kit()
+	kit.Placeholders = templateKitPlaceholders
+	kit.Language = "go"
+	metadata, err := yaml.Marshal(kit)
+	if err != nil {
+		return fmt.Errorf("failed to encode kit metadata: %w", err)
+	}
+
+	err = km.writeTemplateKit(tmpl, kitPath, metadata)
+	if err == nil {
+		err = km.ValidateKit(kitPath)
+	}
+	if err != nil {
+		os.RemoveAll(kitPath)
+		return fmt.Errorf("failed to install template '%s': %w", tmpl.Name, err)
+	}
+
+	gl.Log("info", fmt.Sprintf("Kit '%s' added successfully", tmpl.Name))
+	return nil
+}
+
+func (km *KitManagerImpl) writeTemplateKit(tmpl *DiscoveredTemplate, kitPath string, metadata []byte) error {
+	if err := os.MkdirAll(filepath.Join(kitPath, engine.KitTemplatesDir), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(kitPath, "metadata.yaml"), metadata, 0644); err != nil {
+		return err
+	}
+
+	return fs.WalkDir(tmpl.Root.FS, tmpl.Name, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == tmpl.Name {
+			return nil
+		}
+		rel := strings.TrimPrefix(name, tmpl.Name+"/")
+		if rel == templateMetadataFile {
+			return nil
+		}
+
+		// Partials move to the kit root; everything else is a kit template
+		target := filepath.Join(kitPath, engine.KitTemplatesDir, filepath.FromSlash(rel))
+		if strings.HasPrefix(rel+"/", engine.PartialsDir+"/") {
+			target = filepath.Join(kitPath, filepath.FromSlash(rel))
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			link, err := engine.ReadLink(tmpl.Root.FS, name)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+
+		content, err := fs.ReadFile(tmpl.Root.FS, name)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm()|0200)
+	})
+}
@@ -0,0 +1,158 @@
+// Package license provides an embedded catalog of common SPDX licenses used
+// to write LICENSE files and source headers into generated projects.
+package license
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed texts/*.txt
+var texts embed.FS
+
+// Placeholders in the embedded texts
+const (
+	yearToken   = "[year]"
+	holderToken = "[fullname]"
+)
+
+// License describes a license in the catalog
+type License struct {
+	ID      string   // SPDX identifier, or "proprietary"
+	Name    string   // Human readable name
+	Aliases []string // Other spellings accepted by Lookup
+
+	text   string // Embedded text, when shared by several identifiers; defaults to ID
+	notice string // Grant written above the text, naming the versions that apply
+}
+
+var catalog = []License{
+	{ID: "MIT", Name: "MIT License"},
+	{ID: "Apache-2.0", Name: "Apache License 2.0", Aliases: []string{"Apache", "Apache2", "Apache 2.0"}},
+	{ID: "BSD-2-Clause", Name: "BSD 2-Clause \"Simplified\" License", Aliases: []string{"BSD-2", "BSD2"}},
+	{ID: "BSD-3-Clause", Name: "BSD 3-Clause \"New\" or \"Revised\" License", Aliases: []string{"BSD-3", "BSD3", "BSD"}},
+	{ID: "MPL-2.0", Name: "Mozilla Public License 2.0", Aliases: []string{"MPL", "MPL2"}},
+	// The bare GPL-3.0 and AGPL-3.0 identifiers are deprecated in SPDX and mean -only
+	{ID: "GPL-3.0-only", Name: "GNU General Public License v3.0 only", Aliases: []string{"GPL-3.0"}, text: "GPL-3.0", notice: gnuNotice("GNU General Public License", false)},
+	{ID: "GPL-3.0-or-later", Name: "GNU General Public License v3.0 or later", Aliases: []string{"GPL-3.0+", "GPL3", "GPLv3", "GPL"}, text: "GPL-3.0", notice: gnuNotice("GNU General Public License", true)},
+	{ID: "AGPL-3.0-only", Name: "GNU Affero General Public License v3.0 only", Aliases: []string{"AGPL-3.0"}, text: "AGPL-3.0", notice: gnuNotice("GNU Affero General Public License", false)},
+	{ID: "AGPL-3.0-or-later", Name: "GNU Affero General Public License v3.0 or later", Aliases: []string{"AGPL-3.0+", "AGPL3", "AGPLv3", "AGPL"}, text: "AGPL-3.0", notice: gnuNotice("GNU Affero General Public License", true)},
+	{ID: "proprietary", Name: "Proprietary (all rights reserved)", Aliases: []string{"Proprietary", "UNLICENSED", "closed"}},
+}
+
+// gnuNotice returns the notice the FSF recommends placing above a GNU
+// license, granting version 3 only or any later version as well
+func gnuNotice(name string, orLater bool) string {
+	versions := "version 3 of the License."
+	if orLater {
+		versions = "either version 3 of the License, or\n(at your option) any later version."
+	}
+	return fmt.Sprintf(`Copyright (C) %s  %s
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the %s as published by
+the Free Software Foundation, %s
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+%s for more details.`, yearToken, holderToken, name, versions, name)
+}
+
+// List returns every license in the catalog
+func List() []License {
+	licenses := make([]License, len(catalog))
+	copy(licenses, catalog)
+	return licenses
+}
+
+// IDs returns the catalog identifiers in catalog order
+func IDs() []string {
+	ids := make([]string, len(catalog))
+	for i, l := range catalog {
+		ids[i] = l.ID
+	}
+	return ids
+}
+
+// Lookup finds a license by SPDX identifier or alias, ignoring case
+func Lookup(id string) (License, bool) {
+	id = strings.TrimSpace(id)
+	for _, l := range catalog {
+		if strings.EqualFold(l.ID, id) {
+			return l, true
+		}
+		for _, alias := range l.Aliases {
+			if strings.EqualFold(alias, id) {
+				return l, true
+			}
+		}
+	}
+	return License{}, false
+}
+
+// Validate resolves id to its canonical identifier or explains what is accepted
+func Validate(id string) (string, error) {
+	l, ok := Lookup(id)
+	if !ok {
+		return "", fmt.Errorf("unknown license '%s' (available: %s; see 'gocrafter license list')", id, strings.Join(IDs(), ", "))
+	}
+	return l.ID, nil
+}
+
+// Text renders the full license text for the copyright holder and year,
+// preceded by its notice if it has one
+func (l License) Text(holder string, year int) (string, error) {
+	name := l.text
+	if name == "" {
+		name = l.ID
+	}
+	data, err := texts.ReadFile("texts/" + name + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("license text for %s not found: %w", l.ID, err)
+	}
+
+	text := strings.TrimRight(string(data), "\n ")
+	if l.notice != "" {
+		text = l.notice + "\n\n" + text
+	}
+	replacer := strings.NewReplacer(yearToken, strconv.Itoa(year), holderToken, holder)
+	return replacer.Replace(text) + "\n", nil
+}
+
+// SPDXIdentifier is the value used in SPDX-License-Identifier headers
+func (l License) SPDXIdentifier() string {
+	if l.ID == "proprietary" {
+		return "LicenseRef-Proprietary"
+	}
+	return l.ID
+}
+
+// GoHeader returns the comment block injected at the top of Go files
+func (l License) GoHeader(holder string, year int) string {
+	return fmt.Sprintf("// Copyright (c) %d %s\n// SPDX-License-Identifier: %s\n", year, holder, l.SPDXIdentifier())
+}
+
+// InjectGoHeader prepends header to a Go source file. Files that already carry
+// an SPDX identifier or are marked as generated code are returned unchanged.
+func InjectGoHeader(src []byte, header string) []byte {
+	if bytes.Contains(src, []byte("SPDX-License-Identifier:")) {
+		return src
+	}
+	firstLine := src
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		firstLine = src[:i]
+	}
+	if bytes.HasPrefix(firstLine, []byte("// Code generated")) {
+		return src
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString("\n")
+	buf.Write(src)
+	return buf.Bytes()
+}
@@ -0,0 +1,151 @@
+package license
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string // Canonical identifier; empty when unknown
+	}{
+		{"MIT", "MIT"},
+		{"mit", "MIT"},
+		{"  Apache-2.0 ", "Apache-2.0"},
+		{"apache 2.0", "Apache-2.0"},
+		{"BSD", "BSD-3-Clause"},
+		{"bsd2", "BSD-2-Clause"},
+		{"MPL", "MPL-2.0"},
+		{"GPL-3.0-only", "GPL-3.0-only"},
+		{"GPL-3.0-or-later", "GPL-3.0-or-later"},
+		{"GPL-3.0", "GPL-3.0-only"},
+		{"GPL-3.0+", "GPL-3.0-or-later"},
+		{"gplv3", "GPL-3.0-or-later"},
+		{"AGPL-3.0-only", "AGPL-3.0-only"},
+		{"AGPL-3.0-or-later", "AGPL-3.0-or-later"},
+		{"agpl-3.0", "AGPL-3.0-only"},
+		{"AGPL", "AGPL-3.0-or-later"},
+		{"UNLICENSED", "proprietary"},
+		{"WTFPL", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		l, ok := Lookup(tt.id)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("Lookup(%q) = %s, want not found", tt.id, l.ID)
+			}
+			continue
+		}
+		if !ok || l.ID != tt.want {
+			t.Errorf("Lookup(%q) = %q, %v; want %q", tt.id, l.ID, ok, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if id, err := Validate("gpl"); err != nil || id != "GPL-3.0-or-later" {
+		t.Errorf(`Validate("gpl") = %q, %v; want GPL-3.0-or-later`, id, err)
+	}
+	_, err := Validate("nope")
+	if err == nil || !strings.Contains(err.Error(), "AGPL-3.0-only") {
+		t.Errorf(`Validate("nope") = %v, want an error listing the catalog`, err)
+	}
+}
+
+func TestCatalogIsUnambiguous(t *testing.T) {
+	seen := make(map[string]string)
+	for _, l := range List() {
+		for _, name := range append([]string{l.ID}, l.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok && other != l.ID {
+				t.Errorf("%q names both %s and %s", name, other, l.ID)
+			}
+			seen[key] = l.ID
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, l := range List() {
+		text, err := l.Text("Jane Doe", 2026)
+		if err != nil {
+			t.Errorf("%s: %v", l.ID, err)
+			continue
+		}
+		if strings.Contains(text, yearToken) || strings.Contains(text, holderToken) {
+			t.Errorf("%s: text keeps placeholders", l.ID)
+		}
+		if !strings.HasSuffix(text, "\n") || strings.HasSuffix(text, "\n\n") {
+			t.Errorf("%s: text doesn't end with a single newline", l.ID)
+		}
+	}
+
+	tests := []struct {
+		id, title, grant string
+		orLater          bool
+	}{
+		{"GPL-3.0-only", "GNU GENERAL PUBLIC LICENSE", "GNU General Public License as published by\nthe Free Software Foundation, version 3 of the License.", false},
+		{"GPL-3.0-or-later", "GNU GENERAL PUBLIC LICENSE", "GNU General Public License as published by\nthe Free Software Foundation, either version 3", true},
+		{"AGPL-3.0-only", "GNU AFFERO GENERAL PUBLIC LICENSE", "GNU Affero General Public License as published by\nthe Free Software Foundation, version 3 of the License.", false},
+		{"AGPL-3.0-or-later", "GNU AFFERO GENERAL PUBLIC LICENSE", "GNU Affero General Public License as published by\nthe Free Software Foundation, either version 3", true},
+	}
+	for _, tt := range tests {
+		l, _ := Lookup(tt.id)
+		text, err := l.Text("Jane Doe", 2026)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.id, err)
+		}
+		if !strings.HasPrefix(text, "Copyright (C) 2026  Jane Doe\n") {
+			t.Errorf("%s: text doesn't start with the copyright notice", tt.id)
+		}
+		if !strings.Contains(text, tt.grant) {
+			t.Errorf("%s: text lacks grant %q", tt.id, tt.grant)
+		}
+		title := strings.Index(text, tt.title)
+		if title < 0 {
+			t.Errorf("%s: text lacks %q", tt.id, tt.title)
+			continue
+		}
+		if got := strings.Contains(text[:title], "any later version"); got != tt.orLater {
+			t.Errorf("%s: notice grants later versions = %v, want %v", tt.id, got, tt.orLater)
+		}
+	}
+}
+
+func TestGoHeader(t *testing.T) {
+	tests := []struct {
+		id, spdx string
+	}{
+		{"MIT", "MIT"},
+		{"GPL-3.0", "GPL-3.0-only"},
+		{"GPL-3.0-or-later", "GPL-3.0-or-later"},
+		{"AGPL", "AGPL-3.0-or-later"},
+		{"proprietary", "LicenseRef-Proprietary"},
+	}
+	for _, tt := range tests {
+		l, _ := Lookup(tt.id)
+		want := "// Copyright (c) 2026 Jane Doe\n// SPDX-License-Identifier: " + tt.spdx + "\n"
+		if got := l.GoHeader("Jane Doe", 2026); got != want {
+			t.Errorf("%s: GoHeader = %q, want %q", tt.id, got, want)
+		}
+	}
+}
+
+func TestInjectGoHeader(t *testing.T) {
+	header := "// SPDX-License-Identifier: MIT\n"
+	tests := []struct {
+		name, src, want string
+	}{
+		{"plain", "package main\n", header + "\npackage main\n"},
+		{"already licensed", "// SPDX-License-Identifier: Apache-2.0\npackage main\n", "// SPDX-License-Identifier: Apache-2.0\npackage main\n"},
+		{"generated", "// Code generated by protoc. DO NOT EDIT.\npackage pb\n", "// Code generated by protoc. DO NOT EDIT.\npackage pb\n"},
+	}
+	for _, tt := range tests {
+		if got := string(InjectGoHeader([]byte(tt.src), header)); got != tt.want {
+			t.Errorf("%s: InjectGoHeader = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// OrgProfileFile is the organization-wide profile looked up from the working directory upwards
+	OrgProfileFile = ".gocrafter/profile.yaml"
+	// activeProfileFile stores the name of the profile selected with `profile use`
+	activeProfileFile = "active"
+)
+
+// ErrProfileNotFound is returned when a named profile does not exist
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Store manages named answer profiles stored as YAML files
+type Store struct {
+	profilesPath string
+}
+
+// NewStore creates a profile store rooted at ~/.gocrafter/profiles
+func NewStore() (*Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return NewStoreAt(filepath.Join(homeDir, ".gocrafter", "profiles")), nil
+}
+
+// NewStoreAt creates a profile store rooted at the given directory
+func NewStoreAt(profilesPath string) *Store {
+	return &Store{profilesPath: profilesPath}
+}
+
+// Path returns the directory where profiles are stored
+func (s *Store) Path() string {
+	return s.profilesPath
+}
+
+// List returns the names of all stored profiles
+func (s *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(s.profilesPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ext))
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// Load reads a named profile
+func (s *Store) Load(name string) (*types.Profile, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		path := filepath.Join(s.profilesPath, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return LoadFile(path)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+}
+
+// Save writes a profile to the store, creating the directory if needed
+func (s *Store) Save(p *types.Profile) error {
+	if err := validateName(p.Name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.profilesPath, 0755); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	path := filepath.Join(s.profilesPath, p.Name+".yaml")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+
+	return nil
+}
+
+// validateName checks that a profile name is a plain file name in the store,
+// so no name can address a file outside it
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if strings.ContainsAny(name, "/\\:*?\"<>|") || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid profile name '%s': it must not contain path characters or start with a dot", name)
+	}
+	return nil
+}
+
+// Active returns the name of the profile selected with Use, or an empty string
+func (s *Store) Active() string {
+	data, err := os.ReadFile(filepath.Join(s.profilesPath, activeProfileFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Use marks a profile as the default for subsequent generations
+func (s *Store) Use(name string) error {
+	if name != "" {
+		if _, err := s.Load(name); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(s.profilesPath, 0755); err != nil {
+		return fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(s.profilesPath, activeProfileFile), []byte(name+"\n"), 0644)
+}
+
+// Resolve returns the effective profile for a generation.
+// The organization profile found from startDir provides the base values and
+// the named profile (or the active one when name is empty) overrides them.
+// A nil profile is returned when neither exists.
+func (s *Store) Resolve(name, startDir string) (*types.Profile, error) {
+	var result *types.Profile
+
+	if orgPath := FindOrgProfile(startDir); orgPath != "" {
+		orgProfile, err := LoadFile(orgPath)
+		if err != nil {
+			return nil, err
+		}
+		result = orgProfile
+	}
+
+	if name == "" {
+		name = s.Active()
+	}
+	if name != "" {
+		userProfile, err := s.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		result = result.Merge(userProfile)
+	}
+
+	return result, nil
+}
+
+// LoadFile reads a profile from a YAML file
+func LoadFile(path string) (*types.Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+
+	var p types.Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse profile %s: %w", path, err)
+	}
+
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	p.Source = path
+	return &p, nil
+}
+
+// FindOrgProfile walks up from startDir looking for .gocrafter/profile.yaml
+func FindOrgProfile(startDir string) string {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		candidate := filepath.Join(dir, OrgProfileFile)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SetField sets a profile field addressed by key.
+// Top-level keys match the YAML names (author, license, ...), while
+// placeholders.<name> and project.<field> address the nested sections.
+func SetField(p *types.Profile, key, value string) error {
+	switch {
+	case strings.HasPrefix(key, "placeholders."):
+		if p.Placeholders == nil {
+			p.Placeholders = make(map[string]string)
+		}
+		p.Placeholders[strings.TrimPrefix(key, "placeholders.")] = value
+		return nil
+	case strings.HasPrefix(key, "project."):
+		return setProjectField(&p.Project, strings.TrimPrefix(key, "project."), value)
+	}
+
+	switch key {
+	case "description":
+		p.Description = value
+	case "author":
+		p.Author = value
+	case "email":
+		p.Email = value
+	case "license":
+		p.License = value
+	case "organization", "org":
+		p.Organization = value
+	case "registry":
+		p.Registry = value
+	case "module_prefix":
+		p.ModulePrefix = value
+	case "git_branch":
+		p.GitBranch = value
+	default:
+		return fmt.Errorf("unknown profile key '%s'", key)
+	}
+
+	return nil
+}
+
+func setProjectField(project *types.ProfileProject, key, value string) error {
+	switch {
+	case strings.HasPrefix(key, "custom."):
+		if project.Custom == nil {
+			project.Custom = make(map[string]string)
+		}
+		project.Custom[strings.TrimPrefix(key, "custom.")] = value
+		return nil
+	}
+
+	switch key {
+	case "database":
+		project.Database = value
+	case "cache":
+		project.Cache = value
+	case "queue":
+		project.Queue = value
+	case "ci":
+		project.CI = value
+	case "monitoring":
+		project.Monitoring = splitList(value)
+	case "features":
+		project.Features = splitList(value)
+	case "docker", "kubernetes":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean for project.%s: %s", key, value)
+		}
+		if key == "docker" {
+			project.Docker = &enabled
+		} else {
+			project.Kubernetes = &enabled
+		}
+	default:
+		return fmt.Errorf("unknown profile key 'project.%s'", key)
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,118 @@
+package profile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStoreAt(filepath.Join(t.TempDir(), "profiles"))
+
+	docker := true
+	want := &types.Profile{
+		Name:         "backend-team",
+		Author:       "Jane Doe",
+		License:      "Apache-2.0",
+		ModulePrefix: "github.com/acme",
+		Placeholders: map[string]string{"port": "8080"},
+		Project: types.ProfileProject{
+			Database:   "postgres",
+			Docker:     &docker,
+			Monitoring: []string{"prometheus", "jaeger"},
+		},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := store.Load("backend-team")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Source != filepath.Join(store.Path(), "backend-team.yaml") {
+		t.Errorf("Source = %q", got.Source)
+	}
+	got.Source = ""
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+
+	names, err := store.List()
+	if err != nil || !reflect.DeepEqual(names, []string{"backend-team"}) {
+		t.Errorf("List = %v, %v; want [backend-team]", names, err)
+	}
+
+	if err := store.Use("backend-team"); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	if active := store.Active(); active != "backend-team" {
+		t.Errorf("Active = %q, want backend-team", active)
+	}
+
+	if _, err := store.Load("missing"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Load(missing) = %v, want ErrProfileNotFound", err)
+	}
+}
+
+func TestStoreRejectsPathNames(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreAt(filepath.Join(dir, "profiles"))
+
+	// A YAML file outside the store that a traversing name would reach
+	outside := filepath.Join(dir, "secret.yaml")
+	if err := os.WriteFile(outside, []byte("name: secret\nauthor: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(store.Path(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"", "../secret", "..", ".hidden", "a/b", `a\b`, "c:x", "what?"} {
+		if _, err := store.Load(name); err == nil || errors.Is(err, ErrProfileNotFound) {
+			t.Errorf("Load(%q) = %v, want an invalid name error", name, err)
+		}
+		if err := store.Save(&types.Profile{Name: name}); err == nil {
+			t.Errorf("Save(%q) succeeded", name)
+		}
+		if err := store.Use(name); name != "" && err == nil {
+			t.Errorf("Use(%q) succeeded", name)
+		}
+	}
+
+	if _, err := store.Resolve("../secret", dir); err == nil {
+		t.Error("Resolve(../secret) succeeded")
+	}
+}
+
+func TestResolveMergesOrgProfile(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreAt(filepath.Join(dir, "profiles"))
+
+	project := filepath.Join(dir, "repo", "svc")
+	if err := os.MkdirAll(filepath.Join(dir, "repo", ".gocrafter"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	org := "name: org\nauthor: Acme\nlicense: MIT\n"
+	if err := os.WriteFile(filepath.Join(dir, "repo", OrgProfileFile), []byte(org), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&types.Profile{Name: "me", Author: "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Resolve("me", project)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.Author != "Jane" || got.License != "MIT" {
+		t.Errorf("Resolve = author %q, license %q; want Jane, MIT", got.Author, got.License)
+	}
+}
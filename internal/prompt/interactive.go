@@ -7,12 +7,15 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/rafa-mori/gocrafter/internal/generator"
+	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 )
 
 // InteractivePrompt handles interactive configuration prompts
 type InteractivePrompt struct {
-	config *generator.ProjectConfig
+	config    *generator.ProjectConfig
+	profile   *types.Profile
+	templates []*generator.DiscoveredTemplate
 }
 
 // NewInteractivePrompt creates a new interactive prompt handler
@@ -22,6 +25,19 @@ func NewInteractivePrompt() *InteractivePrompt {
 	}
 }
 
+// WithProfile sets the profile whose values are offered as prompt defaults
+func (p *InteractivePrompt) WithProfile(profile *types.Profile) *InteractivePrompt {
+	p.profile = profile
+	p.config.ApplyProfile(profile)
+	return p
+}
+
+// WithTemplates sets the templates offered by the wizard
+func (p *InteractivePrompt) WithTemplates(templates []*generator.DiscoveredTemplate) *InteractivePrompt {
+	p.templates = templates
+	return p
+}
+
 // Run executes the interactive prompt flow
 func (p *InteractivePrompt) Run() (*generator.ProjectConfig, error) {
 	gl.Log("info", "Starting interactive project setup")
@@ -64,56 +80,49 @@ func (p *InteractivePrompt) Run() (*generator.ProjectConfig, error) {
 }
 
 func (p *InteractivePrompt) promptProjectInfo() error {
-	questions := []*survey.Question{
-		{
-			Name: "name",
-			Prompt: &survey.Input{
-				Message: "What's your project name?",
-				Help:    "This will be used as the directory name and default package name",
-			},
-			Validate: survey.Required,
-		},
-		{
-			Name: "module",
-			Prompt: &survey.Input{
-				Message: "What's your Go module name?",
-				Help:    "e.g., github.com/username/project-name",
-			},
-			Validate: survey.Required,
-		},
+	var name string
+	namePrompt := &survey.Input{
+		Message: "What's your project name?",
+		Help:    "This will be used as the directory name and default package name",
 	}
 
-	answers := struct {
-		Name   string
-		Module string
-	}{}
+	err := survey.AskOne(namePrompt, &name, survey.WithValidator(survey.Required))
+	if err != nil {
+		return err
+	}
 
-	err := survey.Ask(questions, &answers)
+	// Suggest a module path from the profile's module prefix
+	var defaultModule string
+	if p.profile != nil && p.profile.ModulePrefix != "" {
+		defaultModule = strings.TrimSuffix(p.profile.ModulePrefix, "/") + "/" + name
+	}
+
+	var module string
+	modulePrompt := &survey.Input{
+		Message: "What's your Go module name?",
+		Help:    "e.g., github.com/username/project-name",
+		Default: defaultModule,
+	}
+
+	err = survey.AskOne(modulePrompt, &module, survey.WithValidator(survey.Required))
 	if err != nil {
 		return err
 	}
 
-	p.config.Name = answers.Name
-	p.config.Module = answers.Module
+	p.config.Name = name
+	p.config.Module = module
 
 	return nil
 }
 
 func (p *InteractivePrompt) promptTemplate() error {
-	templates := generator.SupportedTemplates()
-	templateDescriptions := map[string]string{
-		"api-rest":     "REST API with HTTP server (Gin/Fiber)",
-		"cli-tool":     "Command-line application with Cobra",
-		"microservice": "Microservice with gRPC and HTTP",
-		"grpc-service": "Pure gRPC service",
-		"worker":       "Background worker/job processor",
-		"library":      "Go library/package",
+	if len(p.templates) == 0 {
+		return fmt.Errorf("no usable templates found. Run 'gocrafter list' for details")
 	}
 
-	options := make([]string, len(templates))
-	for i, tmpl := range templates {
-		desc := templateDescriptions[tmpl]
-		options[i] = fmt.Sprintf("%s - %s", tmpl, desc)
+	options := make([]string, len(p.templates))
+	for i, tmpl := range p.templates {
+		options[i] = fmt.Sprintf("%s - %s", tmpl.Name, tmpl.Info.Description)
 	}
 
 	var selected string
@@ -147,6 +156,7 @@ func (p *InteractivePrompt) promptDatabase() error {
 	prompt := &survey.Select{
 		Message: "Which database do you want to use?",
 		Options: databases,
+		Default: defaultOption(databases, p.config.Database),
 		Help:    "Select 'none' if you don't need a database",
 	}
 
@@ -166,6 +176,7 @@ func (p *InteractivePrompt) promptDatabase() error {
 		cachePrompt := &survey.Select{
 			Message: "Do you want to add a cache layer?",
 			Options: caches,
+			Default: defaultOption(caches, p.config.Cache),
 		}
 
 		err = survey.AskOne(cachePrompt, &cacheSelected)
@@ -175,7 +186,12 @@ func (p *InteractivePrompt) promptDatabase() error {
 
 		if cacheSelected != "none" {
 			p.config.Cache = cacheSelected
+		} else {
+			p.config.Cache = ""
 		}
+	} else {
+		p.config.Database = ""
+		p.config.Cache = ""
 	}
 
 	return nil
@@ -196,9 +212,18 @@ func (p *InteractivePrompt) promptFeatures() error {
 	}
 
 	var selected []string
+	// Preselect profile features that are offered as options
+	var defaults []string
+	for _, feature := range p.config.Features {
+		if defaultOption(features, feature) != nil {
+			defaults = append(defaults, feature)
+		}
+	}
+
 	prompt := &survey.MultiSelect{
 		Message: "Which additional features do you want to include?",
 		Options: features,
+		Default: defaults,
 		Help:    "Select all features you want to include in your project",
 	}
 
@@ -217,7 +242,7 @@ func (p *InteractivePrompt) promptDevOps() error {
 	var includeDocker bool
 	dockerPrompt := &survey.Confirm{
 		Message: "Include Docker configuration?",
-		Default: true,
+		Default: p.config.Docker,
 		Help:    "Includes Dockerfile and docker-compose.yml",
 	}
 
@@ -232,7 +257,7 @@ func (p *InteractivePrompt) promptDevOps() error {
 		var includeK8s bool
 		k8sPrompt := &survey.Confirm{
 			Message: "Include Kubernetes manifests?",
-			Default: false,
+			Default: p.config.Kubernetes,
 			Help:    "Includes deployment, service, and configmap YAML files",
 		}
 
@@ -251,6 +276,7 @@ func (p *InteractivePrompt) promptDevOps() error {
 	ciPrompt := &survey.Select{
 		Message: "Which CI/CD system do you want to use?",
 		Options: ciSystems,
+		Default: defaultOption(ciSystems, p.config.CI),
 		Help:    "This will generate appropriate workflow files",
 	}
 
@@ -261,6 +287,8 @@ func (p *InteractivePrompt) promptDevOps() error {
 
 	if selectedCI != "none" {
 		p.config.CI = selectedCI
+	} else {
+		p.config.CI = ""
 	}
 
 	return nil
@@ -307,8 +335,19 @@ func (p *InteractivePrompt) confirmConfiguration() error {
 	return nil
 }
 
+// defaultOption returns value when it is one of the options, so it can be used
+// as a survey default, or nil otherwise
+func defaultOption(options []string, value string) interface{} {
+	for _, option := range options {
+		if option == value {
+			return value
+		}
+	}
+	return nil
+}
+
 // QuickPrompt runs a simplified prompt for quick project generation
-func QuickPrompt(template string) (*generator.ProjectConfig, error) {
+func QuickPrompt(template string, profile *types.Profile) (*generator.ProjectConfig, error) {
 	config := generator.NewProjectConfig()
 	config.Template = template
 
@@ -341,6 +380,7 @@ func QuickPrompt(template string) (*generator.ProjectConfig, error) {
 
 	config.Name = answers.Name
 	config.Module = answers.Module
+	config.ApplyProfile(profile)
 
 	return config, nil
 }
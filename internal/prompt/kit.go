@@ -5,16 +5,26 @@ import (
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/rafa-mori/gocrafter/internal/license"
 	"github.com/rafa-mori/gocrafter/internal/types"
 	gl "github.com/rafa-mori/gocrafter/logger"
 )
 
 // KitPrompt handles prompting for kit-specific placeholders
-type KitPrompt struct{}
+type KitPrompt struct {
+	profile *types.Profile
+	// ask reads one answer, returning defaultValue when the user enters nothing
+	ask func(message, defaultValue string) (string, error)
+}
 
 // NewKitPrompt creates a new kit prompt instance
 func NewKitPrompt() *KitPrompt {
-	return &KitPrompt{}
+	return &KitPrompt{ask: askInput}
+}
+
+// NewKitPromptWithProfile creates a kit prompt that offers profile values as defaults
+func NewKitPromptWithProfile(profile *types.Profile) *KitPrompt {
+	return &KitPrompt{profile: profile, ask: askInput}
 }
 
 // PromptForPlaceholders prompts the user for placeholder values
@@ -65,26 +75,42 @@ func (kp *KitPrompt) promptForSinglePlaceholder(placeholder string) (string, err
 	// Get default value and prompt message based on placeholder name
 	defaultValue, promptMessage := kp.getPlaceholderDefaults(placeholder)
 
+	value, err := kp.ask(promptMessage, defaultValue)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
+// askInput asks for a line of text on the terminal
+func askInput(message, defaultValue string) (string, error) {
 	var value string
 	prompt := &survey.Input{
-		Message: promptMessage,
+		Message: message,
 		Default: defaultValue,
 	}
+	err := survey.AskOne(prompt, &value)
+	return value, err
+}
 
-	if err := survey.AskOne(prompt, &value); err != nil {
-		return "", err
+// getPlaceholderDefaults returns default values and prompt messages for common placeholders.
+// Values from the active profile take precedence over the built-in defaults.
+func (kp *KitPrompt) getPlaceholderDefaults(placeholder string) (string, string) {
+	defaultValue, promptMessage := kp.getBuiltinPlaceholderDefaults(placeholder)
+	if value, ok := kp.profile.PlaceholderDefault(placeholder); ok {
+		return value, promptMessage
 	}
-
-	return strings.TrimSpace(value), nil
+	return defaultValue, promptMessage
 }
 
-// getPlaceholderDefaults returns default values and prompt messages for common placeholders
-func (kp *KitPrompt) getPlaceholderDefaults(placeholder string) (string, string) {
+// getBuiltinPlaceholderDefaults returns the hardcoded defaults for well-known placeholders
+func (kp *KitPrompt) getBuiltinPlaceholderDefaults(placeholder string) (string, string) {
 	switch strings.ToLower(placeholder) {
 	case "author":
 		return "", "Author name:"
 	case "license":
-		return "MIT", "License (MIT, Apache-2.0, GPL-3.0, etc.):"
+		return "MIT", fmt.Sprintf("License (%s):", strings.Join(license.IDs(), ", "))
 	case "description":
 		return "", "Project description:"
 	case "version":
@@ -177,7 +203,7 @@ func (kp *KitPrompt) humanizePlaceholderName(placeholder string) string {
 	// Replace underscores and hyphens with spaces
 	humanized := strings.ReplaceAll(placeholder, "_", " ")
 	humanized = strings.ReplaceAll(humanized, "-", " ")
-	
+
 	// Capitalize first letter of each word
 	words := strings.Fields(humanized)
 	for i, word := range words {
@@ -185,7 +211,7 @@ func (kp *KitPrompt) humanizePlaceholderName(placeholder string) string {
 			words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
 		}
 	}
-	
+
 	return strings.Join(words, " ")
 }
 
@@ -228,7 +254,7 @@ func (kp *KitPrompt) ConfirmGeneration(kitName, projectName, outputPath string,
 	gl.Log("info", fmt.Sprintf("   Kit: %s", kitName))
 	gl.Log("info", fmt.Sprintf("   Project: %s", projectName))
 	gl.Log("info", fmt.Sprintf("   Output: %s", outputPath))
-	
+
 	if len(placeholders) > 0 {
 		gl.Log("info", "   Placeholders:")
 		for _, p := range placeholders {
@@ -237,7 +263,7 @@ func (kp *KitPrompt) ConfirmGeneration(kitName, projectName, outputPath string,
 			}
 		}
 	}
-	
+
 	gl.Log("info", "")
 
 	var confirm bool
@@ -0,0 +1,58 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rafa-mori/gocrafter/internal/types"
+)
+
+func TestPromptForPlaceholdersProfileDefaults(t *testing.T) {
+	kp := NewKitPromptWithProfile(&types.Profile{
+		Author:       "Ana Lima",
+		License:      "Apache-2.0",
+		Placeholders: map[string]string{"port": "9090", "team": "platform"},
+	})
+
+	// The user accepts every default except the port
+	defaults := make(map[string]string)
+	kp.ask = func(message, defaultValue string) (string, error) {
+		defaults[message] = defaultValue
+		if message == "Default port:" {
+			return " 3000 ", nil
+		}
+		return defaultValue, nil
+	}
+
+	values, err := kp.PromptForPlaceholders(
+		[]string{"author", "license", "port", "region", "database"},
+		[]types.PlaceholderValue{{Name: "database", Value: "postgres"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantDefaults := map[string]string{
+		"Author name:":  "Ana Lima",
+		"Default port:": "9090",
+		"AWS region:":   "us-east-1",
+	}
+	for message, want := range wantDefaults {
+		if got, ok := defaults[message]; !ok || got != want {
+			t.Errorf("%q offered %q, want %q", message, got, want)
+		}
+	}
+	if len(defaults) != 4 {
+		t.Errorf("asked %d questions, want 4 (database already had a value)", len(defaults))
+	}
+
+	want := []types.PlaceholderValue{
+		{Name: "author", Value: "Ana Lima"},
+		{Name: "license", Value: "Apache-2.0"},
+		{Name: "port", Value: "3000"},
+		{Name: "region", Value: "us-east-1"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("PromptForPlaceholders = %v, want %v", values, want)
+	}
+}
@@ -17,7 +17,49 @@ type Kit struct {
 	Tags         []string          `yaml:"tags"`
 	LocalPath    string            `yaml:"-"` // Path where kit is stored locally
 	InstallDate  time.Time         `yaml:"-"` // When kit was installed
+	Linked       bool              `yaml:"-"` // Registered by reference (kit add --link); LocalPath is the user's directory
+	Broken       string            `yaml:"-"` // Why a linked kit can't be loaded, e.g. its directory was removed
+	Root         string            `yaml:"-"` // Kit root the kit was found in: project, env, user or system
+	ShadowedBy   string            `yaml:"-"` // Root of a kit with the same name that takes precedence, if any
 	Metadata     map[string]string `yaml:"metadata,omitempty"`
+	// Delimiters replaces the default {{ }} action delimiters for the whole kit
+	Delimiters *Delimiters `yaml:"delimiters,omitempty"`
+	// FileDelimiters overrides the delimiters for files matching a glob; the first match wins
+	FileDelimiters []FileDelimiters `yaml:"file_delimiters,omitempty"`
+	// Render lists globs that are always rendered, even if they look binary
+	Render []string `yaml:"render,omitempty"`
+	// Copy lists globs that are always copied verbatim
+	Copy []string `yaml:"copy,omitempty"`
+	// Conditions maps globs to expressions; matching files are only generated when the expression holds
+	Conditions map[string]string `yaml:"conditions,omitempty"`
+	// Foreach maps globs to list placeholders; each matching file is rendered once per element
+	Foreach map[string]string `yaml:"foreach,omitempty"`
+	// Modes maps globs to octal permissions overriding the source file mode
+	Modes map[string]string `yaml:"modes,omitempty"`
+	// PostProcess names the post-processors to run; empty runs the language's formatters
+	PostProcess []string `yaml:"post_process,omitempty"`
+	// Git sets repository defaults for projects generated from the kit
+	Git *KitGit `yaml:"git,omitempty"`
+	// LicenseHeaders adds a copyright and SPDX header to every generated Go file
+	LicenseHeaders bool `yaml:"license_headers,omitempty"`
+}
+
+// KitGit holds a kit's git repository defaults
+type KitGit struct {
+	Init   bool   `yaml:"init"`             // Initialize a repository unless --git=false is given
+	Branch string `yaml:"branch,omitempty"` // Default branch name
+}
+
+// Delimiters are the left and right action delimiters used in templates
+type Delimiters struct {
+	Left  string `yaml:"left"`
+	Right string `yaml:"right"`
+}
+
+// FileDelimiters sets the delimiters for template files matching Glob
+type FileDelimiters struct {
+	Glob       string `yaml:"glob"`
+	Delimiters `yaml:",inline"`
 }
 
 // KitManager handles kit operations
@@ -38,10 +80,15 @@ type KitManager interface {
 
 // KitConfig represents the configuration for kit management
 type KitConfig struct {
-	KitsPath    string `yaml:"kits_path"`
-	CachePath   string `yaml:"cache_path"`
-	AutoUpdate  bool   `yaml:"auto_update"`
-	MaxCacheAge int    `yaml:"max_cache_age_days"`
+	KitsPath   string `yaml:"kits_path"`
+	CachePath  string `yaml:"cache_path"`
+	AutoUpdate bool   `yaml:"auto_update"`
+	// MaxCacheAge is when kits go stale and cached downloads expire, in days; 0 is never
+	MaxCacheAge int `yaml:"max_cache_age_days"`
+	// MaxCacheSize caps the download cache, in megabytes; 0 is unlimited
+	MaxCacheSize int `yaml:"max_cache_size_mb"`
+	// Registries lists registry index URLs or paths searched by kit search and kit add <name>
+	Registries []string `yaml:"registries,omitempty"`
 }
 
 // PlaceholderValue represents a placeholder and its value
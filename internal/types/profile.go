@@ -0,0 +1,159 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// Profile represents a reusable set of default answers for project generation
+type Profile struct {
+	Name         string            `yaml:"name"`
+	Description  string            `yaml:"description,omitempty"`
+	Author       string            `yaml:"author,omitempty"`
+	Email        string            `yaml:"email,omitempty"`
+	License      string            `yaml:"license,omitempty"`
+	Organization string            `yaml:"organization,omitempty"`
+	Registry     string            `yaml:"registry,omitempty"`
+	ModulePrefix string            `yaml:"module_prefix,omitempty"`
+	GitBranch    string            `yaml:"git_branch,omitempty"`
+	Placeholders map[string]string `yaml:"placeholders,omitempty"`
+	Project      ProfileProject    `yaml:"project,omitempty"`
+	Source       string            `yaml:"-"` // File the profile was loaded from
+}
+
+// ProfileProject holds profile defaults for built-in template projects
+type ProfileProject struct {
+	Database   string            `yaml:"database,omitempty"`
+	Cache      string            `yaml:"cache,omitempty"`
+	Queue      string            `yaml:"queue,omitempty"`
+	Monitoring []string          `yaml:"monitoring,omitempty"`
+	Docker     *bool             `yaml:"docker,omitempty"`
+	Kubernetes *bool             `yaml:"kubernetes,omitempty"`
+	CI         string            `yaml:"ci,omitempty"`
+	Features   []string          `yaml:"features,omitempty"`
+	Custom     map[string]string `yaml:"custom,omitempty"`
+}
+
+// Merge returns a new profile where non-empty values from other override p
+func (p *Profile) Merge(other *Profile) *Profile {
+	merged := &Profile{
+		Placeholders: make(map[string]string),
+		Project: ProfileProject{
+			Custom: make(map[string]string),
+		},
+	}
+
+	for _, src := range []*Profile{p, other} {
+		if src == nil {
+			continue
+		}
+
+		merged.Name = pick(src.Name, merged.Name)
+		merged.Description = pick(src.Description, merged.Description)
+		merged.Author = pick(src.Author, merged.Author)
+		merged.Email = pick(src.Email, merged.Email)
+		merged.License = pick(src.License, merged.License)
+		merged.Organization = pick(src.Organization, merged.Organization)
+		merged.Registry = pick(src.Registry, merged.Registry)
+		merged.ModulePrefix = pick(src.ModulePrefix, merged.ModulePrefix)
+		merged.GitBranch = pick(src.GitBranch, merged.GitBranch)
+		merged.Source = pick(src.Source, merged.Source)
+
+		for name, value := range src.Placeholders {
+			merged.Placeholders[name] = value
+		}
+
+		merged.Project.Database = pick(src.Project.Database, merged.Project.Database)
+		merged.Project.Cache = pick(src.Project.Cache, merged.Project.Cache)
+		merged.Project.Queue = pick(src.Project.Queue, merged.Project.Queue)
+		merged.Project.CI = pick(src.Project.CI, merged.Project.CI)
+		if len(src.Project.Monitoring) > 0 {
+			merged.Project.Monitoring = src.Project.Monitoring
+		}
+		if len(src.Project.Features) > 0 {
+			merged.Project.Features = src.Project.Features
+		}
+		if src.Project.Docker != nil {
+			merged.Project.Docker = src.Project.Docker
+		}
+		if src.Project.Kubernetes != nil {
+			merged.Project.Kubernetes = src.Project.Kubernetes
+		}
+		for name, value := range src.Project.Custom {
+			merged.Project.Custom[name] = value
+		}
+	}
+
+	return merged
+}
+
+// PlaceholderDefault returns the profile value for a placeholder name, if any
+func (p *Profile) PlaceholderDefault(placeholder string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	if value, ok := p.Placeholders[placeholder]; ok {
+		return value, true
+	}
+
+	var value string
+	switch strings.ToLower(placeholder) {
+	case "author":
+		value = p.Author
+	case "email":
+		value = p.Email
+	case "license":
+		value = p.License
+	case "organization", "org":
+		value = p.Organization
+	case "container_registry", "registry":
+		value = p.Registry
+	case "module_prefix":
+		value = p.ModulePrefix
+	}
+
+	return value, value != ""
+}
+
+// PlaceholderValues returns the profile answers as placeholder values
+func (p *Profile) PlaceholderValues() []PlaceholderValue {
+	if p == nil {
+		return nil
+	}
+
+	var values []PlaceholderValue
+	for _, name := range []string{"author", "email", "license", "organization", "container_registry"} {
+		if value, ok := p.PlaceholderDefault(name); ok {
+			values = append(values, PlaceholderValue{Name: name, Value: value})
+		}
+	}
+
+	names := make([]string, 0, len(p.Placeholders))
+	for name := range p.Placeholders {
+		if !isProfileField(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values = append(values, PlaceholderValue{Name: name, Value: p.Placeholders[name]})
+	}
+
+	return values
+}
+
+func isProfileField(name string) bool {
+	switch name {
+	case "author", "email", "license", "organization", "container_registry":
+		return true
+	}
+	return false
+}
+
+func pick(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,22 @@
+package gocrafter
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// builtinTemplates holds the project templates shipped in the binary. Go
+// sources and go.mod carry a .tmpl suffix so they don't join this module;
+// the suffix is stripped when a project is generated.
+//
+//go:embed all:templates
+var builtinTemplates embed.FS
+
+// Templates returns the built-in templates, one directory per template
+func Templates() fs.FS {
+	templates, err := fs.Sub(builtinTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}